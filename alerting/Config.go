@@ -0,0 +1,86 @@
+package alerting
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+)
+
+//Config controls the windows, thresholds and delivery of the alert evaluator
+type Config struct {
+	RecentWindow   time.Duration //performance over this window, ending now, is checked
+	BaselineWindow time.Duration //against performance over this window, ending where the recent window starts
+	Cooldown       time.Duration //an alert is not raised again for the same carrier, lane and metric within this time
+	Thresholds     analytics.AlertThresholds
+	WebhookURL     string //alerts are only stored when empty
+	WebhookSecret  string
+}
+
+//DefaultConfig compares the last week with the four weeks before it
+var DefaultConfig = Config{
+	RecentWindow:   7 * 24 * time.Hour,
+	BaselineWindow: 28 * 24 * time.Hour,
+	Cooldown:       24 * time.Hour,
+	Thresholds:     analytics.DefaultAlertThresholds,
+}
+
+//ConfigFromEnv overrides DefaultConfig with any of the ALERT_* variables that are set:
+//ALERT_RECENT_WINDOW, ALERT_BASELINE_WINDOW and ALERT_COOLDOWN as durations (e.g. 168h),
+//ALERT_TRANSIT_INCREASE_RATIO, ALERT_EXCEPTION_RATE_INCREASE, ALERT_MIN_SAMPLES, ALERT_WEBHOOK_URL and ALERT_WEBHOOK_SECRET
+func ConfigFromEnv(getenv func(string) string) (Config, error) {
+	config := DefaultConfig
+
+	durations := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"ALERT_RECENT_WINDOW", &config.RecentWindow},
+		{"ALERT_BASELINE_WINDOW", &config.BaselineWindow},
+		{"ALERT_COOLDOWN", &config.Cooldown},
+	}
+	for _, d := range durations {
+		if raw := getenv(d.name); len(raw) != 0 {
+			parsed, err := time.ParseDuration(raw)
+			if err != nil || parsed < 0 {
+				return config, fmt.Errorf("Invalid %s %q", d.name, raw)
+			}
+			*d.value = parsed
+		}
+	}
+
+	floats := []struct {
+		name  string
+		value *float64
+	}{
+		{"ALERT_TRANSIT_INCREASE_RATIO", &config.Thresholds.TransitIncreaseRatio},
+		{"ALERT_EXCEPTION_RATE_INCREASE", &config.Thresholds.ExceptionRateIncrease},
+	}
+	for _, f := range floats {
+		if raw := getenv(f.name); len(raw) != 0 {
+			parsed, err := strconv.ParseFloat(raw, 64)
+			if err != nil || parsed < 0 {
+				return config, fmt.Errorf("Invalid %s %q", f.name, raw)
+			}
+			*f.value = parsed
+		}
+	}
+
+	if raw := getenv("ALERT_MIN_SAMPLES"); len(raw) != 0 {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			return config, fmt.Errorf("Invalid ALERT_MIN_SAMPLES %q", raw)
+		}
+		config.Thresholds.MinSamples = parsed
+	}
+
+	if config.RecentWindow == 0 || config.BaselineWindow == 0 {
+		return config, fmt.Errorf("ALERT_RECENT_WINDOW and ALERT_BASELINE_WINDOW must be positive")
+	}
+
+	config.WebhookURL = getenv("ALERT_WEBHOOK_URL")
+	config.WebhookSecret = getenv("ALERT_WEBHOOK_SECRET")
+
+	return config, nil
+}
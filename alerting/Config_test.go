@@ -0,0 +1,54 @@
+package alerting_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/alerting"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(name string) string { return values[name] }
+	}
+
+	config, err := alerting.ConfigFromEnv(env(nil))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if config.RecentWindow != alerting.DefaultConfig.RecentWindow || config.Thresholds != alerting.DefaultConfig.Thresholds || len(config.WebhookURL) != 0 {
+		t.Errorf("expected defaults, got %+v", config)
+	}
+
+	config, err = alerting.ConfigFromEnv(env(map[string]string{
+		"ALERT_RECENT_WINDOW":           "48h",
+		"ALERT_COOLDOWN":                "6h",
+		"ALERT_TRANSIT_INCREASE_RATIO":  "1.5",
+		"ALERT_EXCEPTION_RATE_INCREASE": "0.1",
+		"ALERT_MIN_SAMPLES":             "10",
+		"ALERT_WEBHOOK_URL":             "https://example.com/hook",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if config.RecentWindow != 48*time.Hour || config.Cooldown != 6*time.Hour || config.BaselineWindow != alerting.DefaultConfig.BaselineWindow {
+		t.Errorf("unexpected windows %+v", config)
+	}
+	if config.Thresholds.TransitIncreaseRatio != 1.5 || config.Thresholds.ExceptionRateIncrease != 0.1 || config.Thresholds.MinSamples != 10 {
+		t.Errorf("unexpected thresholds %+v", config.Thresholds)
+	}
+	if config.WebhookURL != "https://example.com/hook" {
+		t.Errorf("unexpected webhook %q", config.WebhookURL)
+	}
+
+	for _, invalid := range []map[string]string{
+		{"ALERT_RECENT_WINDOW": "a week"},
+		{"ALERT_BASELINE_WINDOW": "0s"},
+		{"ALERT_TRANSIT_INCREASE_RATIO": "-1"},
+		{"ALERT_MIN_SAMPLES": "0"},
+	} {
+		if _, err := alerting.ConfigFromEnv(env(invalid)); err == nil {
+			t.Errorf("expected an error for %v", invalid)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package alerting
+
+import (
+	"context"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//Summary reports what one evaluation did
+type Summary struct {
+	Tenants          int `json:"tenants"`
+	Raised           int `json:"raised"`
+	Suppressed       int `json:"suppressed"` //anomalies still inside the cooldown of an earlier alert
+	DeliveryFailures int `json:"delivery_failures"`
+}
+
+//Evaluator compares every tenant's recent carrier performance with its baseline and raises alerts
+type Evaluator struct {
+	conn    *dataAccess.SQLConnection
+	log     *logging.Logger
+	config  Config
+	webhook *integrations.AlertWebhook
+}
+
+func NewEvaluator(conn *dataAccess.SQLConnection, config Config, log *logging.Logger) *Evaluator {
+	var webhook *integrations.AlertWebhook
+	if len(config.WebhookURL) != 0 {
+		webhook = integrations.NewAlertWebhook(config.WebhookURL, config.WebhookSecret)
+	}
+
+	return &Evaluator{
+		conn:    conn.WithLogger(log),
+		log:     log,
+		config:  config,
+		webhook: webhook,
+	}
+}
+
+//Run evaluates every tenant with tracking activity in the recent window ending at now. Queries are cancelled with the context.
+//A failing tenant does not stop the others; the first error is returned with the summary.
+func (ev Evaluator) Run(ctx context.Context, now time.Time) (*Summary, error) {
+	recentStart := now.Add(-ev.config.RecentWindow)
+	baselineStart := recentStart.Add(-ev.config.BaselineWindow)
+
+	tenantIDs, err := ev.conn.AlertManager().ListActiveTenants(ctx, recentStart)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{}
+	var firstErr error
+	for _, tenantID := range tenantIDs {
+		summary.Tenants++
+		err := ev.evaluateTenant(ctx, tenantID, now, recentStart, baselineStart, summary)
+		if err != nil {
+			ev.log.Error("tenant evaluation failed", logging.KeyTenantID, tenantID, logging.KeyError, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return summary, firstErr
+}
+
+func (ev Evaluator) evaluateTenant(ctx context.Context, tenantID string, now, recentStart, baselineStart time.Time, summary *Summary) error {
+	shipmentManager := ev.conn.ShipmentManager(tenantID)
+	alertManager := ev.conn.AlertManager()
+
+	recent, err := shipmentManager.GetPerformanceWindows(ctx, recentStart, now)
+	if err != nil {
+		return err
+	}
+	baseline, err := shipmentManager.GetPerformanceWindows(ctx, baselineStart, recentStart)
+	if err != nil {
+		return err
+	}
+
+	//store new alerts, skipping those raised recently
+	raised := []*models.Alert{}
+	for _, alert := range analytics.DetectAnomalies(recent, baseline, ev.config.Thresholds) {
+		alert.TenantID = tenantID
+
+		lastAlert, err := alertManager.GetLastAlertTime(ctx, alert)
+		if err != nil {
+			return err
+		}
+		if !lastAlert.IsZero() && now.Sub(lastAlert) < ev.config.Cooldown {
+			summary.Suppressed++
+			continue
+		}
+
+		if _, err := alertManager.InsertAlert(ctx, alert); err != nil {
+			return err
+		}
+		summary.Raised++
+		raised = append(raised, alert)
+	}
+
+	if ev.webhook == nil || len(raised) == 0 {
+		return nil
+	}
+
+	//deliver, recording the outcome on each alert so failed deliveries stay visible in the history
+	deliveryErr := ev.webhook.Send(raised)
+	if deliveryErr != nil {
+		ev.log.Error("alert delivery failed", logging.KeyTenantID, tenantID, "alerts", len(raised), logging.KeyError, deliveryErr)
+		summary.DeliveryFailures += len(raised)
+	}
+	for _, alert := range raised {
+		if err := alertManager.RecordDelivery(ctx, alert.AlertID, deliveryErr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package analytics
+
+import (
+	"math"
+
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//AlertThresholds configures when recent performance counts as anomalous against the baseline
+type AlertThresholds struct {
+	TransitIncreaseRatio  float64 //alert when the recent median transit time exceeds the baseline median by this factor, e.g. 1.25
+	ExceptionRateIncrease float64 //alert when the recent exception rate exceeds the baseline rate by this many points, e.g. 0.05
+	MinSamples            int     //minimum shipments in both windows before either metric is compared
+}
+
+//DefaultAlertThresholds flags a 25% slowdown or a 5 point rise in exceptions over at least 20 shipments
+var DefaultAlertThresholds = AlertThresholds{
+	TransitIncreaseRatio:  1.25,
+	ExceptionRateIncrease: 0.05,
+	MinSamples:            20,
+}
+
+//DetectAnomalies compares each recent carrier or lane window with the baseline window for the same carrier and lane and returns an alert for every threshold exceeded.
+//Returned alerts carry no tenant or ID.
+func DetectAnomalies(recent []*models.PerformanceWindow, baseline []*models.PerformanceWindow, thresholds AlertThresholds) []*models.Alert {
+	type laneKey struct{ carrier, origin, destination string }
+	baselines := map[laneKey]*models.PerformanceWindow{}
+	for _, window := range baseline {
+		baselines[laneKey{window.Carrier, window.OriginState, window.DestinationState}] = window
+	}
+
+	alerts := []*models.Alert{}
+	for _, window := range recent {
+		base := baselines[laneKey{window.Carrier, window.OriginState, window.DestinationState}]
+		if base == nil {
+			continue
+		}
+
+		newAlert := func(metric string, baselineValue, recentValue, threshold float64, baselineSamples, recentSamples int) *models.Alert {
+			return &models.Alert{
+				Carrier:          window.Carrier,
+				OriginState:      window.OriginState,
+				DestinationState: window.DestinationState,
+				Metric:           metric,
+				BaselineValue:    baselineValue,
+				RecentValue:      recentValue,
+				ThresholdValue:   threshold,
+				BaselineSamples:  baselineSamples,
+				RecentSamples:    recentSamples,
+			}
+		}
+
+		//transit time, over delivered shipments only
+		if thresholds.TransitIncreaseRatio > 0 && window.DeliveredCount >= thresholds.MinSamples && base.DeliveredCount >= thresholds.MinSamples && base.MedianTransitHours > 0 {
+			threshold := base.MedianTransitHours * thresholds.TransitIncreaseRatio
+			if window.MedianTransitHours > threshold {
+				alerts = append(alerts, newAlert(models.AlertMetricMedianTransit,
+					roundHours(base.MedianTransitHours), roundHours(window.MedianTransitHours), roundHours(threshold),
+					base.DeliveredCount, window.DeliveredCount))
+			}
+		}
+
+		//exception rate, over every active shipment
+		if thresholds.ExceptionRateIncrease > 0 && window.ShipmentCount >= thresholds.MinSamples && base.ShipmentCount >= thresholds.MinSamples {
+			threshold := math.Min(base.ExceptionRate+thresholds.ExceptionRateIncrease, 1)
+			if window.ExceptionRate > threshold {
+				alerts = append(alerts, newAlert(models.AlertMetricExceptionRate,
+					roundRate(base.ExceptionRate), roundRate(window.ExceptionRate), roundRate(threshold),
+					base.ShipmentCount, window.ShipmentCount))
+			}
+		}
+	}
+
+	return alerts
+}
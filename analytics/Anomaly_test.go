@@ -0,0 +1,60 @@
+package analytics_test
+
+import (
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	baseline := []*models.PerformanceWindow{
+		{Carrier: "ups", ShipmentCount: 100, DeliveredCount: 90, MedianTransitHours: 48, ExceptionRate: 0.02},
+		{Carrier: "ups", OriginState: "CA", DestinationState: "NY", ShipmentCount: 40, DeliveredCount: 35, MedianTransitHours: 96, ExceptionRate: 0.05},
+		{Carrier: "fedex", ShipmentCount: 100, DeliveredCount: 90, MedianTransitHours: 40, ExceptionRate: 0.01},
+		{Carrier: "usps", ShipmentCount: 5, DeliveredCount: 5, MedianTransitHours: 50, ExceptionRate: 0},
+	}
+	recent := []*models.PerformanceWindow{
+		//slower than 1.25x but exceptions within 5 points
+		{Carrier: "ups", ShipmentCount: 50, DeliveredCount: 45, MedianTransitHours: 61, ExceptionRate: 0.06},
+		//lane with more exceptions but normal transit times
+		{Carrier: "ups", OriginState: "CA", DestinationState: "NY", ShipmentCount: 25, DeliveredCount: 20, MedianTransitHours: 100, ExceptionRate: 0.2},
+		//within thresholds
+		{Carrier: "fedex", ShipmentCount: 50, DeliveredCount: 45, MedianTransitHours: 49, ExceptionRate: 0.05},
+		//too few samples
+		{Carrier: "usps", ShipmentCount: 5, DeliveredCount: 5, MedianTransitHours: 500, ExceptionRate: 1},
+		//no baseline
+		{Carrier: "ontrac", ShipmentCount: 50, DeliveredCount: 50, MedianTransitHours: 500, ExceptionRate: 1},
+	}
+
+	alerts := analytics.DetectAnomalies(recent, baseline, analytics.DefaultAlertThresholds)
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 alerts, got %d: %+v", len(alerts), alerts)
+	}
+
+	transit := alerts[0]
+	if transit.Carrier != "ups" || transit.OriginState != "" || transit.Metric != models.AlertMetricMedianTransit {
+		t.Errorf("unexpected transit alert %+v", transit)
+	}
+	if transit.BaselineValue != 48 || transit.RecentValue != 61 || transit.ThresholdValue != 60 || transit.BaselineSamples != 90 || transit.RecentSamples != 45 {
+		t.Errorf("unexpected transit alert values %+v", transit)
+	}
+
+	exceptions := alerts[1]
+	if exceptions.Carrier != "ups" || exceptions.OriginState != "CA" || exceptions.DestinationState != "NY" || exceptions.Metric != models.AlertMetricExceptionRate {
+		t.Errorf("unexpected exception alert %+v", exceptions)
+	}
+	if exceptions.ThresholdValue != 0.1 || exceptions.RecentValue != 0.2 || exceptions.RecentSamples != 25 {
+		t.Errorf("unexpected exception alert values %+v", exceptions)
+	}
+}
+
+func TestDetectAnomaliesDisabledThresholds(t *testing.T) {
+	baseline := []*models.PerformanceWindow{{Carrier: "ups", ShipmentCount: 100, DeliveredCount: 100, MedianTransitHours: 48, ExceptionRate: 0}}
+	recent := []*models.PerformanceWindow{{Carrier: "ups", ShipmentCount: 100, DeliveredCount: 100, MedianTransitHours: 480, ExceptionRate: 1}}
+
+	alerts := analytics.DetectAnomalies(recent, baseline, analytics.AlertThresholds{MinSamples: 1})
+	if len(alerts) != 0 {
+		t.Errorf("expected no alerts with zero thresholds, got %+v", alerts)
+	}
+}
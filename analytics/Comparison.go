@@ -0,0 +1,76 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//RankMetric selects how carriers are ordered in a comparison
+type RankMetric string
+
+const (
+	RankByMedianTransit RankMetric = "median_transit"
+	RankByP90Transit    RankMetric = "p90_transit"
+	RankByOnTimeRate    RankMetric = "on_time_rate"
+	RankByExceptionRate RankMetric = "exception_rate"
+	RankBySampleCount   RankMetric = "sample_count"
+)
+
+//rankMetrics maps each metric to a function returning true when a ranks ahead of b
+var rankMetrics = map[RankMetric]func(a, b *models.CarrierStats) bool{
+	RankByMedianTransit: func(a, b *models.CarrierStats) bool { return a.MedianTransitHours < b.MedianTransitHours },
+	RankByP90Transit:    func(a, b *models.CarrierStats) bool { return a.P90TransitHours < b.P90TransitHours },
+	RankByOnTimeRate:    func(a, b *models.CarrierStats) bool { return a.OnTimeRate > b.OnTimeRate },
+	RankByExceptionRate: func(a, b *models.CarrierStats) bool { return a.ExceptionRate < b.ExceptionRate },
+	RankBySampleCount:   func(a, b *models.CarrierStats) bool { return a.SampleCount > b.SampleCount },
+}
+
+//ParseRankMetric validates a metric name
+func ParseRankMetric(name string) (RankMetric, error) {
+	metric := RankMetric(name)
+	if _, ok := rankMetrics[metric]; !ok {
+		return "", fmt.Errorf("Unknown ranking metric %q, expected median_transit, p90_transit, on_time_rate, exception_rate or sample_count", name)
+	}
+	return metric, nil
+}
+
+//RankCarriers drops groups with fewer than minSamples shipments, and groups without deliveries when ranking by transit time, then orders the rest by the metric.
+//Ties are broken by sample count so better supported numbers come first.
+func RankCarriers(stats []*models.CarrierStats, metric RankMetric, minSamples int) []*models.CarrierStats {
+	less := rankMetrics[metric]
+	if less == nil {
+		less = rankMetrics[RankByMedianTransit]
+	}
+	needsDeliveries := metric == RankByMedianTransit || metric == RankByP90Transit || metric == RankByOnTimeRate
+
+	ranked := []*models.CarrierStats{}
+	for _, stat := range stats {
+		if stat.SampleCount < minSamples || (needsDeliveries && stat.DeliveredCount == 0) {
+			continue
+		}
+
+		stat.MedianTransitHours = roundHours(stat.MedianTransitHours)
+		stat.P90TransitHours = roundHours(stat.P90TransitHours)
+		stat.OnTimeRate = roundRate(stat.OnTimeRate)
+		stat.ExceptionRate = roundRate(stat.ExceptionRate)
+		ranked = append(ranked, stat)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if less(ranked[i], ranked[j]) {
+			return true
+		}
+		if less(ranked[j], ranked[i]) {
+			return false
+		}
+		return ranked[i].SampleCount > ranked[j].SampleCount
+	})
+
+	return ranked
+}
+
+func roundRate(rate float64) float64 {
+	return float64(int(rate*1000+0.5)) / 1000
+}
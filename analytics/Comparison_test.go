@@ -0,0 +1,56 @@
+package analytics_test
+
+import (
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func TestRankCarriers(t *testing.T) {
+	newStats := func() []*models.CarrierStats {
+		return []*models.CarrierStats{
+			{Carrier: "ups", SampleCount: 50, DeliveredCount: 45, MedianTransitHours: 60, P90TransitHours: 100, OnTimeRate: 0.9, ExceptionRate: 0.02},
+			{Carrier: "fedex", SampleCount: 40, DeliveredCount: 40, MedianTransitHours: 48, P90TransitHours: 120, OnTimeRate: 0.95, ExceptionRate: 0.05},
+			{Carrier: "usps", SampleCount: 3, DeliveredCount: 3, MedianTransitHours: 24, P90TransitHours: 30, OnTimeRate: 1, ExceptionRate: 0},
+			{Carrier: "ontrac", SampleCount: 10, DeliveredCount: 0, ExceptionRate: 0.5},
+		}
+	}
+
+	order := func(stats []*models.CarrierStats) []string {
+		var carriers []string
+		for _, stat := range stats {
+			carriers = append(carriers, stat.Carrier)
+		}
+		return carriers
+	}
+
+	cases := []struct {
+		metric   analytics.RankMetric
+		expected []string
+	}{
+		{analytics.RankByMedianTransit, []string{"fedex", "ups"}},
+		{analytics.RankByP90Transit, []string{"ups", "fedex"}},
+		{analytics.RankByOnTimeRate, []string{"fedex", "ups"}},
+		{analytics.RankByExceptionRate, []string{"ups", "fedex", "ontrac"}},
+		{analytics.RankBySampleCount, []string{"ups", "fedex", "ontrac"}},
+	}
+
+	for _, c := range cases {
+		ranked := order(analytics.RankCarriers(newStats(), c.metric, 5))
+		if len(ranked) != len(c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.metric, c.expected, ranked)
+			continue
+		}
+		for i := range ranked {
+			if ranked[i] != c.expected[i] {
+				t.Errorf("%s: expected %v, got %v", c.metric, c.expected, ranked)
+				break
+			}
+		}
+	}
+
+	if _, err := analytics.ParseRankMetric("fastest"); err == nil {
+		t.Error("expected unknown metric to be rejected")
+	}
+}
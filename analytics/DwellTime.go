@@ -0,0 +1,166 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//Visit is a run of consecutive scans of one shipment at the same facility
+type Visit struct {
+	ShipmentID string
+	Carrier    string
+	Facility   string
+	Arrived    time.Time //first scan at the facility
+	Departed   time.Time //last scan at the facility
+}
+
+//Dwell is the time the shipment spent at the facility
+func (visit Visit) Dwell() time.Duration {
+	return visit.Departed.Sub(visit.Arrived)
+}
+
+//Hop is the move between two consecutive facilities of a shipment
+type Hop struct {
+	ShipmentID string
+	Carrier    string
+	From       string
+	To         string
+	Duration   time.Duration //from the last scan at From to the first scan at To
+}
+
+//FacilityDwell summarizes how long shipments of a carrier sit at a facility
+type FacilityDwell struct {
+	Carrier          string  `json:"carrier"`
+	Facility         string  `json:"facility"`
+	MedianDwellHours float64 `json:"median_dwell_hours"`
+	P90DwellHours    float64 `json:"p90_dwell_hours"`
+	MedianHopHours   float64 `json:"median_hop_hours"` //median time to reach the next facility
+	Samples          int     `json:"samples"`
+}
+
+//facilityName identifies a facility by its normalized city and state, empty when the scan has no location
+func facilityName(event *models.ScanEvent) string {
+	if len(event.City) == 0 {
+		return ""
+	}
+	if len(event.State) == 0 {
+		return event.City
+	}
+	return event.City + ", " + event.State
+}
+
+//BuildVisits orders each shipment's scans by time and collapses consecutive scans at the same facility into visits, returning the visits and the hops between them.
+//Scans without a location are ignored.
+func BuildVisits(events []*models.ScanEvent) ([]Visit, []Hop) {
+	byShipment := map[string][]*models.ScanEvent{}
+	var shipmentIDs []string
+	for _, event := range events {
+		if len(facilityName(event)) == 0 {
+			continue
+		}
+		if _, ok := byShipment[event.ShipmentID]; !ok {
+			shipmentIDs = append(shipmentIDs, event.ShipmentID)
+		}
+		byShipment[event.ShipmentID] = append(byShipment[event.ShipmentID], event)
+	}
+
+	var visits []Visit
+	var hops []Hop
+	for _, shipmentID := range shipmentIDs {
+		scans := byShipment[shipmentID]
+		sort.SliceStable(scans, func(i, j int) bool {
+			return scans[i].StatusDate.Before(scans[j].StatusDate)
+		})
+
+		var current *Visit
+		for _, scan := range scans {
+			facility := facilityName(scan)
+			if current != nil && current.Facility == facility {
+				current.Departed = scan.StatusDate
+				continue
+			}
+
+			if current != nil {
+				visits = append(visits, *current)
+				hops = append(hops, Hop{
+					ShipmentID: shipmentID,
+					Carrier:    scan.Carrier,
+					From:       current.Facility,
+					To:         facility,
+					Duration:   scan.StatusDate.Sub(current.Departed),
+				})
+			}
+
+			current = &Visit{
+				ShipmentID: shipmentID,
+				Carrier:    scan.Carrier,
+				Facility:   facility,
+				Arrived:    scan.StatusDate,
+				Departed:   scan.StatusDate,
+			}
+		}
+		if current != nil {
+			visits = append(visits, *current)
+		}
+	}
+
+	return visits, hops
+}
+
+//WorstFacilities returns, per carrier, the facilities with the highest median dwell time.
+//Facilities with fewer than minSamples visits are skipped, and at most limit facilities are returned per carrier.
+func WorstFacilities(events []*models.ScanEvent, minSamples int, limit int) map[string][]*FacilityDwell {
+	visits, hops := BuildVisits(events)
+
+	type facilityKey struct {
+		carrier  string
+		facility string
+	}
+
+	dwells := map[facilityKey][]float64{}
+	for _, visit := range visits {
+		key := facilityKey{visit.Carrier, visit.Facility}
+		dwells[key] = append(dwells[key], visit.Dwell().Hours())
+	}
+
+	hopTimes := map[facilityKey][]float64{}
+	for _, hop := range hops {
+		key := facilityKey{hop.Carrier, hop.From}
+		hopTimes[key] = append(hopTimes[key], hop.Duration.Hours())
+	}
+
+	result := map[string][]*FacilityDwell{}
+	for key, values := range dwells {
+		if len(values) < minSamples {
+			continue
+		}
+		result[key.carrier] = append(result[key.carrier], &FacilityDwell{
+			Carrier:          key.carrier,
+			Facility:         key.facility,
+			MedianDwellHours: roundHours(Median(values)),
+			P90DwellHours:    roundHours(Percentile(values, 90)),
+			MedianHopHours:   roundHours(Median(hopTimes[key])),
+			Samples:          len(values),
+		})
+	}
+
+	for carrier, facilities := range result {
+		sort.Slice(facilities, func(i, j int) bool {
+			if facilities[i].MedianDwellHours != facilities[j].MedianDwellHours {
+				return facilities[i].MedianDwellHours > facilities[j].MedianDwellHours
+			}
+			return facilities[i].Facility < facilities[j].Facility
+		})
+		if limit > 0 && len(facilities) > limit {
+			result[carrier] = facilities[:limit]
+		}
+	}
+
+	return result
+}
+
+func roundHours(hours float64) float64 {
+	return float64(int(hours*100+0.5)) / 100
+}
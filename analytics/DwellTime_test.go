@@ -0,0 +1,79 @@
+package analytics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func TestWorstFacilities(t *testing.T) {
+	start := time.Date(2021, 3, 1, 8, 0, 0, 0, time.UTC)
+	scan := func(shipmentID string, hours float64, city string) *models.ScanEvent {
+		return &models.ScanEvent{
+			ShipmentID: shipmentID,
+			Carrier:    "ups",
+			StatusDate: start.Add(time.Duration(hours * float64(time.Hour))),
+			City:       city,
+			State:      "KY",
+		}
+	}
+
+	events := []*models.ScanEvent{
+		//out of order on purpose, the analysis sorts per shipment
+		scan("a", 30, "Louisville"),
+		scan("a", 0, "Lexington"),
+		scan("a", 2, "Lexington"),
+		scan("a", 10, "Louisville"),
+		scan("a", 40, "Bowling Green"),
+		scan("a", 20, "Louisville"),
+
+		scan("b", 0, "Lexington"),
+		scan("b", 4, "Lexington"),
+		scan("b", 6, "Louisville"),
+		scan("b", 16, "Louisville"),
+		{ShipmentID: "b", Carrier: "ups", StatusDate: start.Add(17 * time.Hour)}, //no location
+		scan("b", 20, "Bowling Green"),
+	}
+
+	visits, hops := analytics.BuildVisits(events)
+	if len(visits) != 6 || len(hops) != 4 {
+		t.Fatalf("expected 6 visits and 4 hops, got %d and %d", len(visits), len(hops))
+	}
+
+	facilities := analytics.WorstFacilities(events, 2, 2)["ups"]
+	if len(facilities) != 2 {
+		t.Fatalf("expected 2 facilities, got %d", len(facilities))
+	}
+
+	worst := facilities[0]
+	if worst.Facility != "Louisville, KY" || worst.Samples != 2 {
+		t.Errorf("unexpected worst facility %+v", worst)
+	}
+	//dwells of 20h and 10h
+	if worst.MedianDwellHours != 15 {
+		t.Errorf("expected median dwell of 15h, got %f", worst.MedianDwellHours)
+	}
+	//hops of 10h and 4h to Bowling Green
+	if worst.MedianHopHours != 7 {
+		t.Errorf("expected median hop of 7h, got %f", worst.MedianHopHours)
+	}
+
+	if facilities[1].Facility != "Lexington, KY" || facilities[1].MedianDwellHours != 3 {
+		t.Errorf("unexpected second facility %+v", facilities[1])
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{4, 1, 3, 2}
+	if median := analytics.Median(values); median != 2.5 {
+		t.Errorf("expected median 2.5, got %f", median)
+	}
+	if p90 := analytics.Percentile([]float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, 90); p90 != 10 {
+		t.Errorf("expected p90 10, got %f", p90)
+	}
+	if analytics.Percentile(nil, 50) != 0 {
+		t.Error("expected zero for no values")
+	}
+}
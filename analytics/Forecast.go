@@ -0,0 +1,144 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/geo"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const (
+	//maxForecastSamples bounds how many historical shipments a forecast reads per grouping
+	maxForecastSamples = 5000
+)
+
+//ErrNoHistory is returned when no delivered shipment matches even the broadest grouping
+var ErrNoHistory = errors.New("No delivered shipments match the request")
+
+//TransitTimeSource provides historical times in transit, implemented by dataAccess.ShipmentsManager
+type TransitTimeSource interface {
+	GetTransitTimes(ctx context.Context, filter models.TransitFilter, limit int) ([]int, error)
+}
+
+//ForecastRequest describes the shipment to forecast
+type ForecastRequest struct {
+	Carrier           string
+	ServiceLevelToken string
+	SpeedClass        string
+	OriginZip3        string
+	DestinationZip3   string
+	ShipDate          time.Time
+	Confidence        float64 //width of the interval, e.g. 0.8 for the 10th to 90th percentile
+	MinSamples        int     //groupings with fewer samples fall back to a broader one
+}
+
+//Forecast is a predicted delivery date with an interval and the history it was based on
+type Forecast struct {
+	EstimatedDelivery  time.Time `json:"estimated_delivery"`
+	EarliestDelivery   time.Time `json:"earliest_delivery"`
+	LatestDelivery     time.Time `json:"latest_delivery"`
+	MedianTransitHours float64   `json:"median_transit_hours"`
+	Confidence         float64   `json:"confidence"`
+	SampleSize         int       `json:"sample_size"`
+	Grouping           string    `json:"grouping"` //which grouping of history was used: lane, zone, service_level or carrier
+}
+
+type forecastGrouping struct {
+	name   string
+	filter models.TransitFilter
+}
+
+//forecastGroupings lists the groupings to try, most specific first. Groupings the request has no data for are skipped.
+func forecastGroupings(request ForecastRequest) []forecastGrouping {
+	service := models.TransitFilter{
+		Carrier:           request.Carrier,
+		ServiceLevelToken: request.ServiceLevelToken,
+		SpeedClass:        request.SpeedClass,
+	}
+
+	var groupings []forecastGrouping
+
+	if len(request.OriginZip3) > 0 && len(request.DestinationZip3) > 0 {
+		lane := service
+		lane.OriginZip3 = request.OriginZip3
+		lane.DestinationZip3 = request.DestinationZip3
+		groupings = append(groupings, forecastGrouping{"lane", lane})
+
+		if lanePath, ok := geo.LaneBetweenZip3s(request.OriginZip3, request.DestinationZip3); ok {
+			zone := service
+			zone.Zone = lanePath.Zone
+			groupings = append(groupings, forecastGrouping{"zone", zone})
+		}
+	}
+
+	if len(service.ServiceLevelToken) > 0 || len(service.SpeedClass) > 0 {
+		groupings = append(groupings, forecastGrouping{"service_level", service})
+	}
+
+	groupings = append(groupings, forecastGrouping{"carrier", models.TransitFilter{Carrier: request.Carrier}})
+
+	return groupings
+}
+
+//ForecastDelivery estimates when a shipment will be delivered from the historical transit times of the most specific grouping with enough samples.
+//When no grouping reaches MinSamples, the broadest grouping with any history is used and SampleSize reports how thin it is.
+func ForecastDelivery(ctx context.Context, source TransitTimeSource, request ForecastRequest) (*Forecast, error) {
+	if request.Confidence <= 0 || request.Confidence >= 1 {
+		request.Confidence = 0.8
+	}
+	if request.ShipDate.IsZero() {
+		request.ShipDate = time.Now()
+	}
+
+	var hours []float64
+	var grouping string
+	for _, candidate := range forecastGroupings(request) {
+		transitTimes, err := source.GetTransitTimes(ctx, candidate.filter, maxForecastSamples)
+		if err != nil {
+			return nil, err
+		}
+		if len(transitTimes) == 0 {
+			continue
+		}
+
+		hours = millisecondsToHours(transitTimes)
+		grouping = candidate.name
+		if len(transitTimes) >= request.MinSamples {
+			break
+		}
+	}
+
+	if len(hours) == 0 {
+		return nil, ErrNoHistory
+	}
+
+	tail := (1 - request.Confidence) / 2 * 100
+	median := Median(hours)
+	lower := Percentile(hours, tail)
+	upper := Percentile(hours, 100-tail)
+
+	return &Forecast{
+		EstimatedDelivery:  request.ShipDate.Add(hoursToDuration(median)),
+		EarliestDelivery:   request.ShipDate.Add(hoursToDuration(lower)),
+		LatestDelivery:     request.ShipDate.Add(hoursToDuration(upper)),
+		MedianTransitHours: roundHours(median),
+		Confidence:         request.Confidence,
+		SampleSize:         len(hours),
+		Grouping:           grouping,
+	}, nil
+}
+
+func millisecondsToHours(milliseconds []int) []float64 {
+	hours := make([]float64, len(milliseconds))
+	for i, value := range milliseconds {
+		hours[i] = float64(value) / float64(time.Hour/time.Millisecond)
+	}
+	return hours
+}
+
+func hoursToDuration(hours float64) time.Duration {
+	return time.Duration(math.Round(hours * float64(time.Hour)))
+}
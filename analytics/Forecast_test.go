@@ -0,0 +1,86 @@
+package analytics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+type fakeTransitTimes struct {
+	byLane    []int
+	byZone    []int
+	byService []int
+	byCarrier []int
+}
+
+func (source fakeTransitTimes) GetTransitTimes(ctx context.Context, filter models.TransitFilter, limit int) ([]int, error) {
+	switch {
+	case len(filter.OriginZip3) > 0:
+		return source.byLane, nil
+	case filter.Zone != 0:
+		return source.byZone, nil
+	case len(filter.ServiceLevelToken) > 0:
+		return source.byService, nil
+	default:
+		return source.byCarrier, nil
+	}
+}
+
+func hoursInMilliseconds(hours ...int) []int {
+	values := make([]int, len(hours))
+	for i, h := range hours {
+		values[i] = h * 3600 * 1000
+	}
+	return values
+}
+
+func TestForecastDeliveryFallsBack(t *testing.T) {
+	source := fakeTransitTimes{
+		byLane:    hoursInMilliseconds(24),
+		byZone:    hoursInMilliseconds(24, 48, 72),
+		byService: hoursInMilliseconds(24, 48, 72, 96, 120),
+		byCarrier: hoursInMilliseconds(24, 48, 72, 96, 120, 144),
+	}
+	shipDate := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	forecast, err := analytics.ForecastDelivery(context.Background(), source, analytics.ForecastRequest{
+		Carrier:           "ups",
+		ServiceLevelToken: "ups_ground",
+		OriginZip3:        "100",
+		DestinationZip3:   "900",
+		ShipDate:          shipDate,
+		MinSamples:        3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if forecast.Grouping != "zone" || forecast.SampleSize != 3 {
+		t.Errorf("expected zone grouping with 3 samples, got %s with %d", forecast.Grouping, forecast.SampleSize)
+	}
+	if !forecast.EstimatedDelivery.Equal(shipDate.Add(48 * time.Hour)) {
+		t.Errorf("unexpected estimate %s", forecast.EstimatedDelivery)
+	}
+	if !forecast.EarliestDelivery.Before(forecast.EstimatedDelivery) || !forecast.LatestDelivery.After(forecast.EstimatedDelivery) {
+		t.Errorf("estimate not inside interval: %+v", forecast)
+	}
+}
+
+func TestForecastDeliveryUsesBroadestWhenThin(t *testing.T) {
+	source := fakeTransitTimes{byCarrier: hoursInMilliseconds(24, 48)}
+
+	forecast, err := analytics.ForecastDelivery(context.Background(), source, analytics.ForecastRequest{Carrier: "ups", MinSamples: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forecast.Grouping != "carrier" || forecast.SampleSize != 2 {
+		t.Errorf("expected thin carrier grouping, got %s with %d", forecast.Grouping, forecast.SampleSize)
+	}
+
+	if _, err := analytics.ForecastDelivery(context.Background(), fakeTransitTimes{}, analytics.ForecastRequest{Carrier: "ups"}); err != analytics.ErrNoHistory {
+		t.Errorf("expected ErrNoHistory, got %v", err)
+	}
+}
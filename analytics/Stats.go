@@ -0,0 +1,55 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+)
+
+//Percentile returns the p-th percentile (0-100) of the values using linear interpolation between closest ranks. The values are sorted in place.
+func Percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sort.Float64s(values)
+
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return values[lower]
+	}
+	return values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+}
+
+//Median returns the median of the values, sorting them in place
+func Median(values []float64) float64 {
+	return Percentile(values, 50)
+}
+
+//Mean returns the arithmetic mean of the values
+func Mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+//StandardDeviation returns the sample standard deviation of the values
+func StandardDeviation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	mean := Mean(values)
+	sum := 0.0
+	for _, value := range values {
+		sum += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
@@ -0,0 +1,138 @@
+package analytics
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//TrendInterval is the width of a time series bucket
+type TrendInterval string
+
+const (
+	TrendIntervalDay   TrendInterval = "day"
+	TrendIntervalWeek  TrendInterval = "week"
+	TrendIntervalMonth TrendInterval = "month"
+
+	//maxTrendBuckets bounds the size of a series
+	maxTrendBuckets = 1000
+)
+
+//ParseTrendInterval validates an interval name
+func ParseTrendInterval(name string) (TrendInterval, error) {
+	switch interval := TrendInterval(name); interval {
+	case TrendIntervalDay, TrendIntervalWeek, TrendIntervalMonth:
+		return interval, nil
+	}
+	return "", fmt.Errorf("Unknown interval %q, expected day, week or month", name)
+}
+
+//TruncateToBucket returns the start of the bucket containing t, in UTC. Weeks start on Monday, matching Postgres date_trunc.
+func TruncateToBucket(t time.Time, interval TrendInterval) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch interval {
+	case TrendIntervalWeek:
+		offset := (int(day.Weekday()) + 6) % 7 //days since Monday
+		return day.AddDate(0, 0, -offset)
+	case TrendIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+func nextBucket(bucket time.Time, interval TrendInterval) time.Time {
+	switch interval {
+	case TrendIntervalWeek:
+		return bucket.AddDate(0, 0, 7)
+	case TrendIntervalMonth:
+		return bucket.AddDate(0, 1, 0)
+	default:
+		return bucket.AddDate(0, 0, 1)
+	}
+}
+
+//Buckets lists the starts of every bucket overlapping [start, end)
+func Buckets(start time.Time, end time.Time, interval TrendInterval) ([]time.Time, error) {
+	if !end.After(start) {
+		return nil, errors.New("end must be after start")
+	}
+
+	var buckets []time.Time
+	for bucket := TruncateToBucket(start, interval); bucket.Before(end); bucket = nextBucket(bucket, interval) {
+		buckets = append(buckets, bucket)
+		if len(buckets) > maxTrendBuckets {
+			return nil, fmt.Errorf("range spans more than %d %s buckets", maxTrendBuckets, interval)
+		}
+	}
+	return buckets, nil
+}
+
+//BuildTrendSeries groups the points by carrier and fills every bucket, so empty buckets appear with a zero count. The listed carriers get a series even without any points.
+//When movingAverageWindow is above one, each point also carries the delivery weighted average of the window ending at it.
+func BuildTrendSeries(points []*models.TrendPoint, buckets []time.Time, carriers []string, movingAverageWindow int) map[string][]*models.TrendPoint {
+	byCarrier := map[string]map[time.Time]*models.TrendPoint{}
+	for _, carrier := range carriers {
+		byCarrier[carrier] = map[time.Time]*models.TrendPoint{}
+	}
+	for _, point := range points {
+		point.AverageTransitHours = roundHoursPtr(point.AverageTransitHours)
+		point.MedianTransitHours = roundHoursPtr(point.MedianTransitHours)
+
+		if _, ok := byCarrier[point.Carrier]; !ok {
+			byCarrier[point.Carrier] = map[time.Time]*models.TrendPoint{}
+		}
+		byCarrier[point.Carrier][point.Bucket.UTC()] = point
+	}
+
+	series := map[string][]*models.TrendPoint{}
+	for carrier, byBucket := range byCarrier {
+		filled := make([]*models.TrendPoint, len(buckets))
+		for i, bucket := range buckets {
+			if point, ok := byBucket[bucket]; ok {
+				filled[i] = point
+			} else {
+				filled[i] = &models.TrendPoint{Bucket: bucket, Carrier: carrier}
+			}
+		}
+
+		if movingAverageWindow > 1 {
+			applyMovingAverage(filled, movingAverageWindow)
+		}
+		series[carrier] = filled
+	}
+
+	return series
+}
+
+//applyMovingAverage weights each bucket's average by its delivery count, so thin buckets do not swing the line
+func applyMovingAverage(points []*models.TrendPoint, window int) {
+	for i := range points {
+		totalHours := 0.0
+		totalCount := 0
+		for j := i - window + 1; j <= i; j++ {
+			if j < 0 || points[j].AverageTransitHours == nil {
+				continue
+			}
+			totalHours += *points[j].AverageTransitHours * float64(points[j].Count)
+			totalCount += points[j].Count
+		}
+
+		if totalCount > 0 {
+			average := roundHours(totalHours / float64(totalCount))
+			points[i].MovingAverageHours = &average
+		}
+	}
+}
+
+func roundHoursPtr(hours *float64) *float64 {
+	if hours == nil {
+		return nil
+	}
+	rounded := roundHours(*hours)
+	return &rounded
+}
@@ -0,0 +1,77 @@
+package analytics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func TestTruncateToBucket(t *testing.T) {
+	//a Wednesday afternoon
+	moment := time.Date(2021, 3, 17, 15, 4, 5, 0, time.UTC)
+
+	cases := map[analytics.TrendInterval]time.Time{
+		analytics.TrendIntervalDay:   time.Date(2021, 3, 17, 0, 0, 0, 0, time.UTC),
+		analytics.TrendIntervalWeek:  time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC),
+		analytics.TrendIntervalMonth: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for interval, expected := range cases {
+		if bucket := analytics.TruncateToBucket(moment, interval); !bucket.Equal(expected) {
+			t.Errorf("%s: expected %s, got %s", interval, expected, bucket)
+		}
+	}
+
+	//Sunday belongs to the week starting the previous Monday
+	sunday := time.Date(2021, 3, 21, 23, 0, 0, 0, time.UTC)
+	if bucket := analytics.TruncateToBucket(sunday, analytics.TrendIntervalWeek); !bucket.Equal(time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected week for Sunday %s", bucket)
+	}
+}
+
+func TestBuildTrendSeries(t *testing.T) {
+	hours := func(h float64) *float64 { return &h }
+	day := func(d int) time.Time { return time.Date(2021, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	buckets, err := analytics.Buckets(day(1), day(5), analytics.TrendIntervalDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets) != 4 {
+		t.Fatalf("expected 4 buckets, got %d", len(buckets))
+	}
+
+	points := []*models.TrendPoint{
+		{Bucket: day(1), Carrier: "ups", Count: 1, AverageTransitHours: hours(10)},
+		{Bucket: day(2), Carrier: "ups", Count: 3, AverageTransitHours: hours(30)},
+		{Bucket: day(4), Carrier: "ups", Count: 2, AverageTransitHours: hours(40)},
+	}
+
+	series := analytics.BuildTrendSeries(points, buckets, []string{"ups", "fedex"}, 2)
+
+	ups := series["ups"]
+	if len(ups) != 4 {
+		t.Fatalf("expected 4 points, got %d", len(ups))
+	}
+	if ups[2].Count != 0 || ups[2].AverageTransitHours != nil {
+		t.Errorf("expected gap to be filled with an empty bucket, got %+v", ups[2])
+	}
+
+	//(10*1 + 30*3) / 4
+	if ups[1].MovingAverageHours == nil || *ups[1].MovingAverageHours != 25 {
+		t.Errorf("unexpected weighted moving average %v", ups[1].MovingAverageHours)
+	}
+	//the empty bucket averages over the previous one only
+	if ups[2].MovingAverageHours == nil || *ups[2].MovingAverageHours != 30 {
+		t.Errorf("unexpected moving average across gap %v", ups[2].MovingAverageHours)
+	}
+
+	if fedex := series["fedex"]; len(fedex) != 4 || fedex[0].Count != 0 {
+		t.Errorf("expected empty series for requested carrier, got %v", fedex)
+	}
+
+	if _, err := analytics.Buckets(day(5), day(1), analytics.TrendIntervalDay); err == nil {
+		t.Error("expected inverted range to be rejected")
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+)
+
+const usage = `usage: apikeys <command> [flags]
+
+commands:
+  create -client ID [-tenant ID] [-name NAME] [-scopes a,b] [-expires DURATION]
+  rotate -key KEY_ID [-grace DURATION]
+  revoke -key KEY_ID
+  list   -client ID
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+
+	databaseConn, err := dataAccess.NewSQLConnection()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer databaseConn.Destroy()
+
+	ctx := context.Background()
+	keyManager := databaseConn.APIKeyManager()
+
+	flags := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	clientID := flags.String("client", "", "client the key belongs to")
+	tenantID := flags.String("tenant", "", "tenant whose data the key acts on (default: the client ID)")
+	name := flags.String("name", "", "human readable key name")
+	scopes := flags.String("scopes", auth.ScopeIngest+","+auth.ScopeAnalytics, "comma separated scopes granted to the key")
+	expires := flags.Duration("expires", 0, "key lifetime, 0 for no expiry")
+	keyID := flags.String("key", "", "key ID")
+	grace := flags.Duration("grace", 24*time.Hour, "how long the old key keeps working after rotation")
+	flags.Parse(os.Args[2:])
+
+	switch os.Args[1] {
+	case "create":
+		var expiresAt *time.Time
+		if *expires > 0 {
+			temp := time.Now().Add(*expires)
+			expiresAt = &temp
+		}
+
+		if len(*tenantID) == 0 {
+			*tenantID = *clientID
+		}
+
+		plaintext, key, err := auth.CreateKey(ctx, keyManager, *clientID, *tenantID, *name, strings.Split(*scopes, ","), expiresAt)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("Key ID: %s\nAPI key: %s\n(store the API key now, it cannot be shown again)\n", key.KeyID, plaintext)
+
+	case "rotate":
+		plaintext, key, err := auth.RotateKey(ctx, keyManager, *keyID, *grace)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("Key ID: %s\nAPI key: %s\nOld key %s expires in %s\n", key.KeyID, plaintext, *keyID, *grace)
+
+	case "revoke":
+		err := keyManager.RevokeAPIKey(ctx, *keyID)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("Revoked %s\n", *keyID)
+
+	case "list":
+		keys, err := keyManager.ListAPIKeys(ctx, *clientID)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		now := time.Now()
+		for _, key := range keys {
+			status := "active"
+			if !key.IsActive(now) {
+				status = "inactive"
+			}
+			fmt.Printf("%s  %s...  %-12s  %-20s  %-8s  %s\n", key.KeyID, key.Prefix, key.TenantID, key.Name, status, strings.Join(key.Scopes, ","))
+		}
+
+	default:
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+}
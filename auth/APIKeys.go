@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const (
+	//ScopeIngest allows ingesting shipments
+	ScopeIngest = "shipments:ingest"
+	//ScopeAnalytics allows reading analytics
+	ScopeAnalytics = "analytics:read"
+	//ScopeAudit allows reading the ingest audit trail
+	ScopeAudit = "audit:read"
+
+	keyPrefix       = "wm_"
+	keyPrefixLength = 11 //"wm_" plus 8 characters, enough to tell keys apart in listings
+)
+
+var (
+	//ErrInvalidKey is returned when the key is unknown, expired or revoked
+	ErrInvalidKey = errors.New("Invalid API key")
+	//ErrForbidden is returned when a valid key lacks the required scope
+	ErrForbidden = errors.New("API key does not allow this operation")
+)
+
+//KeyStore persists API keys, implemented by dataAccess.APIKeyManager
+type KeyStore interface {
+	InsertAPIKey(ctx context.Context, key *models.APIKey) (string, error)
+	GetAPIKey(ctx context.Context, keyID string) (*models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	ExpireAPIKey(ctx context.Context, keyID string, expiresAt time.Time) error
+}
+
+//Client identifies the caller of a request
+type Client struct {
+	ClientID string
+	TenantID string
+	KeyID    string
+	KeyName  string
+}
+
+type clientContextKey struct{}
+
+//WithClient attaches the client to the context
+func WithClient(ctx context.Context, client *Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+//ClientFromContext returns the client attached to the context, or nil for unauthenticated requests
+func ClientFromContext(ctx context.Context) *Client {
+	client, _ := ctx.Value(clientContextKey{}).(*Client)
+	return client
+}
+
+//HashKey returns the hex encoded SHA-256 hash of a plaintext key, which is what gets stored
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+//Authenticate validates a plaintext key and checks that it grants the scope
+func Authenticate(ctx context.Context, store KeyStore, key string, scope string) (*Client, error) {
+	if len(key) == 0 {
+		return nil, ErrInvalidKey
+	}
+
+	apiKey, err := store.GetAPIKeyByHash(ctx, HashKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == nil || !apiKey.IsActive(time.Now()) {
+		return nil, ErrInvalidKey
+	}
+	if len(scope) > 0 && !apiKey.HasScope(scope) {
+		return nil, ErrForbidden
+	}
+
+	return &Client{
+		ClientID: apiKey.ClientID,
+		TenantID: apiKey.TenantID,
+		KeyID:    apiKey.KeyID,
+		KeyName:  apiKey.Name,
+	}, nil
+}
+
+//CreateKey generates and stores a new key for the client, whose requests act on the tenant's data. The plaintext key is returned once and never stored.
+func CreateKey(ctx context.Context, store KeyStore, clientID string, tenantID string, name string, scopes []string, expiresAt *time.Time) (string, *models.APIKey, error) {
+	if len(clientID) == 0 {
+		return "", nil, errors.New("Client ID is required")
+	}
+	if len(tenantID) == 0 {
+		return "", nil, errors.New("Tenant ID is required")
+	}
+
+	plaintext, err := generateKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	apiKey := &models.APIKey{
+		ClientID:  clientID,
+		TenantID:  tenantID,
+		Name:      name,
+		Prefix:    plaintext[:keyPrefixLength],
+		Hash:      HashKey(plaintext),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+
+	apiKey.KeyID, err = store.InsertAPIKey(ctx, apiKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return plaintext, apiKey, nil
+}
+
+//RotateKey issues a replacement for the key with the same client, name and scopes, and expires the old key after the grace period so callers can switch over, or sooner if it was due to expire sooner
+func RotateKey(ctx context.Context, store KeyStore, keyID string, gracePeriod time.Duration) (string, *models.APIKey, error) {
+	oldKey, err := store.GetAPIKey(ctx, keyID)
+	if err != nil {
+		return "", nil, err
+	}
+	if oldKey == nil || !oldKey.IsActive(time.Now()) {
+		return "", nil, ErrInvalidKey
+	}
+
+	plaintext, newKey, err := CreateKey(ctx, store, oldKey.ClientID, oldKey.TenantID, oldKey.Name, oldKey.Scopes, oldKey.ExpiresAt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	//the grace period only ever shortens the old key's life
+	expiresAt := time.Now().Add(gracePeriod)
+	if oldKey.ExpiresAt != nil && oldKey.ExpiresAt.Before(expiresAt) {
+		return plaintext, newKey, nil
+	}
+
+	err = store.ExpireAPIKey(ctx, oldKey.KeyID, expiresAt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return plaintext, newKey, nil
+}
+
+func generateKey() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,121 @@
+package auth_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+type memoryStore struct {
+	keys map[string]*models.APIKey
+}
+
+func (store *memoryStore) InsertAPIKey(ctx context.Context, key *models.APIKey) (string, error) {
+	key.KeyID = strconv.Itoa(len(store.keys) + 1)
+	store.keys[key.KeyID] = key
+	return key.KeyID, nil
+}
+
+func (store *memoryStore) GetAPIKey(ctx context.Context, keyID string) (*models.APIKey, error) {
+	return store.keys[keyID], nil
+}
+
+func (store *memoryStore) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	for _, key := range store.keys {
+		if key.Hash == hash {
+			return key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (store *memoryStore) ExpireAPIKey(ctx context.Context, keyID string, expiresAt time.Time) error {
+	store.keys[keyID].ExpiresAt = &expiresAt
+	return nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	store := &memoryStore{keys: map[string]*models.APIKey{}}
+
+	plaintext, key, err := auth.CreateKey(context.Background(), store, "merchant-1", "tenant-1", "test", []string{auth.ScopeIngest}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Hash == plaintext || key.Hash != auth.HashKey(plaintext) {
+		t.Fatal("key should be stored hashed")
+	}
+
+	client, err := auth.Authenticate(context.Background(), store, plaintext, auth.ScopeIngest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.ClientID != "merchant-1" || client.TenantID != "tenant-1" || client.KeyID != key.KeyID {
+		t.Errorf("unexpected client %+v", client)
+	}
+
+	if _, err := auth.Authenticate(context.Background(), store, plaintext, auth.ScopeAnalytics); err != auth.ErrForbidden {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+	if _, err := auth.Authenticate(context.Background(), store, "wm_unknown", auth.ScopeIngest); err != auth.ErrInvalidKey {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+
+	revokedAt := time.Now().Add(-time.Second)
+	key.RevokedAt = &revokedAt
+	if _, err := auth.Authenticate(context.Background(), store, plaintext, auth.ScopeIngest); err != auth.ErrInvalidKey {
+		t.Errorf("expected revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	store := &memoryStore{keys: map[string]*models.APIKey{}}
+
+	oldPlaintext, oldKey, err := auth.CreateKey(context.Background(), store, "merchant-1", "tenant-1", "test", []string{"*"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newPlaintext, newKey, err := auth.RotateKey(context.Background(), store, oldKey.KeyID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newKey.ClientID != oldKey.ClientID || newPlaintext == oldPlaintext {
+		t.Errorf("unexpected rotated key %+v", newKey)
+	}
+
+	//both keys work during the grace period
+	for _, plaintext := range []string{oldPlaintext, newPlaintext} {
+		if _, err := auth.Authenticate(context.Background(), store, plaintext, auth.ScopeAnalytics); err != nil {
+			t.Errorf("expected key to work during grace period: %v", err)
+		}
+	}
+
+	if _, _, err := auth.RotateKey(context.Background(), store, oldKey.KeyID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.Authenticate(context.Background(), store, oldPlaintext, auth.ScopeAnalytics); err != auth.ErrInvalidKey {
+		t.Errorf("expected old key to expire, got %v", err)
+	}
+}
+
+func TestRotateKeyKeepsEarlierExpiry(t *testing.T) {
+	store := &memoryStore{keys: map[string]*models.APIKey{}}
+
+	expiresAt := time.Now().Add(time.Hour)
+	_, oldKey, err := auth.CreateKey(context.Background(), store, "merchant-1", "tenant-1", "test", []string{"*"}, &expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//a grace period ending after the key expires must not extend it
+	if _, _, err := auth.RotateKey(context.Background(), store, oldKey.KeyID, 24*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.keys[oldKey.KeyID].ExpiresAt; got == nil || !got.Equal(expiresAt) {
+		t.Errorf("expected the old key to still expire at %v, got %v", expiresAt, got)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+//Checkpoint is an append-only record of shipments that were ingested successfully, used to resume an interrupted backfill
+type Checkpoint struct {
+	mutex     sync.Mutex
+	file      *os.File
+	completed map[string]bool
+}
+
+//OpenCheckpoint loads previously completed shipments from path, creating the file if it does not exist.
+//A last line without a newline was torn by an interruption mid-write, it is not trusted and is cut off so the next key starts on a line of its own.
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := ioutil.ReadAll(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if end := bytes.LastIndexByte(contents, '\n') + 1; end < len(contents) {
+		if err := file.Truncate(int64(end)); err != nil {
+			file.Close()
+			return nil, err
+		}
+		contents = contents[:end]
+	}
+
+	completed := map[string]bool{}
+	for _, line := range strings.Split(string(contents), "\n") {
+		key := strings.TrimSpace(line)
+		if len(key) > 0 {
+			completed[key] = true
+		}
+	}
+
+	return &Checkpoint{
+		file:      file,
+		completed: completed,
+	}, nil
+}
+
+func (cp *Checkpoint) IsComplete(shipment Shipment) bool {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	return cp.completed[shipment.Key()]
+}
+
+//MarkComplete records the shipment and flushes it to disk so progress survives an interruption
+func (cp *Checkpoint) MarkComplete(shipment Shipment) error {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	if cp.completed[shipment.Key()] {
+		return nil
+	}
+
+	_, err := cp.file.WriteString(shipment.Key() + "\n")
+	if err != nil {
+		return err
+	}
+	cp.completed[shipment.Key()] = true
+
+	return cp.file.Sync()
+}
+
+func (cp *Checkpoint) Close() error {
+	return cp.file.Close()
+}
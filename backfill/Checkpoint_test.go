@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestCheckpointReplay(t *testing.T) {
+	ups := Shipment{Carrier: "UPS", TrackingCode: "1Z8995V60312565703"}
+	fedex := Shipment{Carrier: "fedex", TrackingCode: "449044304137821"}
+	usps := Shipment{Carrier: "usps", TrackingCode: "9400111899223197428490"}
+
+	tests := []struct {
+		name      string
+		contents  string
+		completed []Shipment
+		pending   []Shipment
+		remaining string //file contents after opening
+	}{
+		{
+			name:    "new file",
+			pending: []Shipment{ups, fedex},
+		},
+		{
+			name:      "completed keys",
+			contents:  "ups,1Z8995V60312565703\n\nfedex,449044304137821\n",
+			completed: []Shipment{ups, fedex},
+			pending:   []Shipment{usps},
+			remaining: "ups,1Z8995V60312565703\n\nfedex,449044304137821\n",
+		},
+		{
+			name:      "torn last line",
+			contents:  "ups,1Z8995V60312565703\nusps,94001118992231",
+			completed: []Shipment{ups},
+			pending:   []Shipment{usps, {Carrier: "usps", TrackingCode: "94001118992231"}},
+			remaining: "ups,1Z8995V60312565703\n",
+		},
+		{
+			name:      "only a torn line",
+			contents:  "fedex,449044304137821",
+			pending:   []Shipment{fedex},
+			remaining: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeFile(t, "backfill.checkpoint", test.contents)
+			checkpoint, err := OpenCheckpoint(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer checkpoint.Close()
+
+			for _, shipment := range test.completed {
+				if !checkpoint.IsComplete(shipment) {
+					t.Errorf("expected %s to be complete", shipment.Key())
+				}
+			}
+			for _, shipment := range test.pending {
+				if checkpoint.IsComplete(shipment) {
+					t.Errorf("expected %s to be pending", shipment.Key())
+				}
+			}
+
+			contents, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(contents) != test.remaining {
+				t.Errorf("expected file %q, got %q", test.remaining, contents)
+			}
+		})
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	ups := Shipment{Carrier: "ups", TrackingCode: "1Z8995V60312565703"}
+	usps := Shipment{Carrier: "usps", TrackingCode: "9400111899223197428490"}
+
+	//the run was interrupted while writing the usps key
+	path := writeFile(t, "backfill.checkpoint", "ups,1Z8995V60312565703\nusps,9400")
+	checkpoint, err := OpenCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkpoint.MarkComplete(usps); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkpoint.MarkComplete(ups); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint.Close()
+
+	//the next run starts the key on its own line and records each shipment once
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "ups,1Z8995V60312565703\nusps,9400111899223197428490\n"; string(contents) != expected {
+		t.Errorf("expected file %q, got %q", expected, contents)
+	}
+
+	checkpoint, err = OpenCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checkpoint.Close()
+	if !checkpoint.IsComplete(ups) || !checkpoint.IsComplete(usps) {
+		t.Error("expected both shipments to be complete after resuming")
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+//Failure records a shipment that could not be ingested
+type Failure struct {
+	Carrier      string `json:"carrier"`
+	TrackingCode string `json:"tracking_code"`
+	Error        string `json:"error"`
+}
+
+//Report summarizes a backfill run
+type Report struct {
+	mutex sync.Mutex
+
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   string    `json:"duration"`
+	Total      int       `json:"total"`
+	Skipped    int       `json:"skipped"` //already completed in a previous run
+	Succeeded  int       `json:"succeeded"`
+	Failed     int       `json:"failed"`
+	Events     int       `json:"events"`
+	Failures   []Failure `json:"failures"`
+}
+
+func (rep *Report) RecordSuccess(eventCount int) {
+	rep.mutex.Lock()
+	defer rep.mutex.Unlock()
+
+	rep.Succeeded++
+	rep.Events += eventCount
+}
+
+func (rep *Report) RecordFailure(shipment Shipment, err error) {
+	rep.mutex.Lock()
+	defer rep.mutex.Unlock()
+
+	rep.Failed++
+	rep.Failures = append(rep.Failures, Failure{
+		Carrier:      shipment.Carrier,
+		TrackingCode: shipment.TrackingCode,
+		Error:        err.Error(),
+	})
+}
+
+//Write saves the report as indented JSON
+func (rep *Report) Write(path string) error {
+	rep.mutex.Lock()
+	defer rep.mutex.Unlock()
+
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
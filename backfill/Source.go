@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//Shipment is a single carrier/tracking code pair to backfill
+type Shipment struct {
+	Carrier      string `json:"carrier"`
+	TrackingCode string `json:"tracking_code"`
+}
+
+//Key uniquely identifies the shipment within a backfill run
+func (s Shipment) Key() string {
+	return strings.ToLower(s.Carrier) + "," + s.TrackingCode
+}
+
+//ReadShipments reads carrier/tracking code pairs from a CSV or JSONL file. When format is empty it is inferred from the file extension.
+func ReadShipments(path string, format string) ([]Shipment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if len(format) == 0 {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	var shipments []Shipment
+	switch format {
+	case "csv":
+		shipments, err = readCSV(file)
+	case "jsonl", "ndjson":
+		shipments, err = readJSONL(file)
+	default:
+		return nil, fmt.Errorf("Unsupported input format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return dedupe(shipments), nil
+}
+
+//dedupe drops repeated shipments, keeping the first, so a shipment listed twice is not ingested by two workers at once
+func dedupe(shipments []Shipment) []Shipment {
+	seen := map[string]bool{}
+	unique := shipments[:0]
+	for _, shipment := range shipments {
+		if seen[shipment.Key()] {
+			continue
+		}
+		seen[shipment.Key()] = true
+		unique = append(unique, shipment)
+	}
+	return unique
+}
+
+//validate rejects rows missing either field, which would otherwise fail one by one during ingest
+func (s Shipment) validate(line int) error {
+	if len(s.Carrier) == 0 || len(s.TrackingCode) == 0 {
+		return fmt.Errorf("line %d: expected carrier and tracking code", line)
+	}
+	return nil
+}
+
+//readCSV reads carrier,tracking_code rows. A header row is optional.
+func readCSV(reader io.Reader) ([]Shipment, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	carrierIndex, codeIndex := 0, 1
+
+	var shipments []Shipment
+	for line := 1; ; line++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		//use header row to locate columns
+		if line == 1 {
+			if index, ok := columnIndex(record, "carrier"); ok {
+				carrierIndex = index
+				if index, ok := columnIndex(record, "tracking_code"); ok {
+					codeIndex = index
+				} else if index, ok := columnIndex(record, "tracking_number"); ok {
+					codeIndex = index
+				} else {
+					return nil, errors.New("CSV header is missing a tracking_code column")
+				}
+				continue
+			}
+		}
+
+		if len(record) <= carrierIndex || len(record) <= codeIndex {
+			return nil, fmt.Errorf("line %d: expected carrier and tracking code", line)
+		}
+
+		shipment := Shipment{
+			Carrier:      strings.TrimSpace(record[carrierIndex]),
+			TrackingCode: strings.TrimSpace(record[codeIndex]),
+		}
+		if err := shipment.validate(line); err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, shipment)
+	}
+
+	return shipments, nil
+}
+
+func columnIndex(header []string, name string) (int, bool) {
+	for i, column := range header {
+		if strings.ToLower(strings.TrimSpace(column)) == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+//readJSONL reads one {"carrier": ..., "tracking_code": ...} object per line
+func readJSONL(reader io.Reader) ([]Shipment, error) {
+	scanner := bufio.NewScanner(reader)
+
+	var shipments []Shipment
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 {
+			continue
+		}
+
+		shipment := Shipment{}
+		err := json.Unmarshal([]byte(text), &shipment)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		shipment.Carrier = strings.TrimSpace(shipment.Carrier)
+		shipment.TrackingCode = strings.TrimSpace(shipment.TrackingCode)
+		if err := shipment.validate(line); err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, shipment)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return shipments, nil
+}
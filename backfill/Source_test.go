@@ -0,0 +1,134 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//writeFile writes contents to name in a temporary directory and returns its path
+func writeFile(t *testing.T, name string, contents string) string {
+	dir, err := ioutil.TempDir("", "backfill")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadShipments(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		contents string
+		format   string
+		expected []Shipment
+		err      string
+	}{
+		{
+			name:     "csv without header",
+			file:     "codes.csv",
+			contents: "ups,1Z8995V60312565703\nusps, 9400111899223197428490\n",
+			expected: []Shipment{{"ups", "1Z8995V60312565703"}, {"usps", "9400111899223197428490"}},
+		},
+		{
+			name:     "csv header locates columns",
+			file:     "codes.csv",
+			contents: "tracking_number,Carrier\n1Z8995V60312565703,ups\n",
+			expected: []Shipment{{"ups", "1Z8995V60312565703"}},
+		},
+		{
+			name:     "csv header without tracking code",
+			file:     "codes.csv",
+			contents: "carrier,code\nups,1Z8995V60312565703\n",
+			err:      "missing a tracking_code column",
+		},
+		{
+			name:     "csv row missing a column",
+			file:     "codes.csv",
+			contents: "ups,1Z8995V60312565703\nfedex\n",
+			err:      "line 2: expected carrier and tracking code",
+		},
+		{
+			name:     "csv row with an empty tracking code",
+			file:     "codes.csv",
+			contents: "ups,1Z8995V60312565703\nups, \n",
+			err:      "line 2: expected carrier and tracking code",
+		},
+		{
+			name:     "csv unterminated quote",
+			file:     "codes.csv",
+			contents: "ups,\"1Z8995V60312565703\n",
+			err:      "quote",
+		},
+		{
+			name:     "csv duplicates keep the first",
+			file:     "codes.csv",
+			contents: "ups,1Z8995V60312565703\nfedex,449044304137821\nUPS,1Z8995V60312565703\n",
+			expected: []Shipment{{"ups", "1Z8995V60312565703"}, {"fedex", "449044304137821"}},
+		},
+		{
+			name:     "jsonl skips blank lines",
+			file:     "codes.jsonl",
+			contents: "{\"carrier\": \"ups\", \"tracking_code\": \"1Z8995V60312565703\"}\n\n{\"carrier\": \"fedex\", \"tracking_code\": \"449044304137821\"}\n",
+			expected: []Shipment{{"ups", "1Z8995V60312565703"}, {"fedex", "449044304137821"}},
+		},
+		{
+			name:     "jsonl malformed line",
+			file:     "codes.jsonl",
+			contents: "{\"carrier\": \"ups\", \"tracking_code\": \"1Z8995V60312565703\"}\n{\"carrier\": \"fedex\",\n",
+			err:      "line 2:",
+		},
+		{
+			name:     "jsonl missing carrier",
+			file:     "codes.jsonl",
+			contents: "{\"tracking_code\": \"1Z8995V60312565703\"}\n",
+			err:      "line 1: expected carrier and tracking code",
+		},
+		{
+			name:     "jsonl duplicates keep the first",
+			file:     "codes.ndjson",
+			contents: "{\"carrier\": \"ups\", \"tracking_code\": \"1Z8995V60312565703\"}\n{\"carrier\": \"ups\", \"tracking_code\": \"1Z8995V60312565703\"}\n",
+			expected: []Shipment{{"ups", "1Z8995V60312565703"}},
+		},
+		{
+			name:     "format overrides the extension",
+			file:     "codes.txt",
+			contents: "ups,1Z8995V60312565703\n",
+			format:   "csv",
+			expected: []Shipment{{"ups", "1Z8995V60312565703"}},
+		},
+		{
+			name:     "unsupported format",
+			file:     "codes.txt",
+			contents: "ups,1Z8995V60312565703\n",
+			err:      "Unsupported input format",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			shipments, err := ReadShipments(writeFile(t, test.file, test.contents), test.format)
+			if len(test.err) != 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error containing %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(shipments, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, shipments)
+			}
+		})
+	}
+}
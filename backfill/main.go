@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+	"github.com/elorusso/wonderment-tech-eval/ingest"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+)
+
+func main() {
+	inputPath := flag.String("input", "", "CSV or JSONL file of carrier/tracking code pairs (required)")
+	tenantID := flag.String("tenant", "", "tenant the shipments are imported for (required)")
+	format := flag.String("format", "", "input format, csv or jsonl (default: inferred from file extension)")
+	concurrency := flag.Int("concurrency", 4, "number of shipments ingested in parallel")
+	rateLimit := flag.Float64("rate", 5, "maximum ingests started per second, 0 for unlimited")
+	checkpointPath := flag.String("checkpoint", "backfill.checkpoint", "file recording completed shipments, used to resume")
+	reportPath := flag.String("report", "backfill-report.json", "file the summary report is written to")
+	flag.Parse()
+
+	if err := run(*inputPath, *tenantID, *format, *concurrency, *rateLimit, *checkpointPath, *reportPath); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func run(inputPath string, tenantID string, format string, concurrency int, rateLimit float64, checkpointPath string, reportPath string) error {
+	if len(inputPath) == 0 {
+		return errors.New("-input is required")
+	}
+	if len(tenantID) == 0 {
+		return errors.New("-tenant is required")
+	}
+	if concurrency < 1 {
+		return errors.New("-concurrency must be at least 1")
+	}
+
+	shipments, err := ReadShipments(inputPath, format)
+	if err != nil {
+		return err
+	}
+
+	checkpoint, err := OpenCheckpoint(checkpointPath)
+	if err != nil {
+		return err
+	}
+	defer checkpoint.Close()
+
+	databaseConn, err := dataAccess.NewSQLConnection()
+	if err != nil {
+		return err
+	}
+	defer databaseConn.Destroy()
+
+	ingestor := ingest.NewIngestor(integrations.NewWondermentAPI(integrations.WondermentBaseURL), databaseConn)
+	log := logging.Default().With(logging.KeyTenantID, tenantID)
+	ctx := logging.WithLogger(context.Background(), log)
+
+	//audit entries name the backfill as the caller and share one request ID per run
+	ctx = auth.WithClient(ctx, &auth.Client{ClientID: "backfill", TenantID: tenantID})
+	ctx = logging.WithRequestID(ctx, fmt.Sprintf("backfill-%d", time.Now().Unix()))
+
+	report := &Report{
+		StartedAt: time.Now(),
+		Total:     len(shipments),
+	}
+
+	//stop handing out work on interrupt, in-flight ingests are allowed to finish
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	var throttle <-chan time.Time
+	if rateLimit > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rateLimit))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	work := make(chan Shipment)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for shipment := range work {
+				result, err := ingestor.IngestShipment(ctx, tenantID, shipment.Carrier, shipment.TrackingCode)
+				if err != nil {
+					log.Error("ingest failed", logging.KeyCarrier, shipment.Carrier, logging.KeyTrackingCode, shipment.TrackingCode, logging.KeyError, err)
+					report.RecordFailure(shipment, err)
+					continue
+				}
+
+				report.RecordSuccess(result.EventCount)
+				if err := checkpoint.MarkComplete(shipment); err != nil {
+					log.Error("checkpoint failed", logging.KeyShipmentID, result.ShipmentID, logging.KeyError, err)
+				}
+			}
+		}()
+	}
+
+	interrupted := false
+dispatch:
+	for i, shipment := range shipments {
+		if checkpoint.IsComplete(shipment) {
+			report.Skipped++
+			continue
+		}
+
+		if throttle != nil {
+			select {
+			case <-throttle:
+			case <-stop:
+				interrupted = true
+				break dispatch
+			}
+		}
+
+		select {
+		case work <- shipment:
+		case <-stop:
+			interrupted = true
+			break dispatch
+		}
+
+		if (i+1)%100 == 0 {
+			fmt.Printf("Dispatched %d/%d\n", i+1, len(shipments))
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	report.FinishedAt = time.Now()
+	report.Duration = report.FinishedAt.Sub(report.StartedAt).String()
+
+	if err := report.Write(reportPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Total: %d, Skipped: %d, Succeeded: %d, Failed: %d, Duration: %s\n",
+		report.Total, report.Skipped, report.Succeeded, report.Failed, report.Duration)
+
+	if interrupted {
+		return errors.New("Backfill interrupted, rerun with the same checkpoint to resume")
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package dataAccess
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+	"github.com/lib/pq"
+)
+
+const (
+	apiKeysTableName = "api_keys"
+)
+
+var apiKeyColumns = []string{
+	"key_id",
+	"client_id",
+	"tenant_id",
+	"name",
+	"key_prefix",
+	"key_hash",
+	"scopes",
+	"created_at",
+	"expires_at",
+	"revoked_at",
+}
+
+type APIKeyManager struct {
+	dbHelper *sql.DB
+	logger   *logging.Logger
+}
+
+//InsertAPIKey stores a new API key and returns its key ID
+func (man APIKeyManager) InsertAPIKey(ctx context.Context, key *models.APIKey) (string, error) {
+	defer observeQuery("APIKeyManager", "InsertAPIKey", time.Now())
+
+	if key == nil {
+		return "", errors.New("nil API key")
+	}
+	if len(key.ClientID) == 0 || len(key.TenantID) == 0 || len(key.Hash) == 0 {
+		return "", errors.New("API key requires a client ID, tenant ID and hash")
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql
+	sql, args, err := psql.Insert(apiKeysTableName).
+		Columns(
+			"client_id",
+			"tenant_id",
+			"name",
+			"key_prefix",
+			"key_hash",
+			"scopes",
+			"expires_at").
+		Values(
+			key.ClientID,
+			key.TenantID,
+			key.Name,
+			key.Prefix,
+			key.Hash,
+			pq.Array(key.Scopes),
+			key.ExpiresAt).
+		Suffix("RETURNING key_id").
+		ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	man.logger.Debug("query", "sql", sql)
+
+	//execute
+	var keyID string
+	err = man.dbHelper.QueryRowContext(ctx, sql, args...).Scan(&keyID)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return "", err
+	}
+
+	return keyID, nil
+}
+
+//GetAPIKeyByHash returns the key with the given hash, or nil if there is none
+func (man APIKeyManager) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	defer observeQuery("APIKeyManager", "GetAPIKeyByHash", time.Now())
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := psql.Select(apiKeyColumns...).From(apiKeysTableName).Where(sq.Eq{"key_hash": hash}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := man.queryAPIKeys(ctx, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return keys[0], nil
+}
+
+//GetAPIKey returns the key with the given key ID, or nil if there is none
+func (man APIKeyManager) GetAPIKey(ctx context.Context, keyID string) (*models.APIKey, error) {
+	defer observeQuery("APIKeyManager", "GetAPIKey", time.Now())
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := psql.Select(apiKeyColumns...).From(apiKeysTableName).Where(sq.Eq{"key_id": keyID}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := man.queryAPIKeys(ctx, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return keys[0], nil
+}
+
+//ListAPIKeys returns every key belonging to the client, newest first
+func (man APIKeyManager) ListAPIKeys(ctx context.Context, clientID string) ([]*models.APIKey, error) {
+	defer observeQuery("APIKeyManager", "ListAPIKeys", time.Now())
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := psql.Select(apiKeyColumns...).From(apiKeysTableName).Where(sq.Eq{"client_id": clientID}).OrderBy("created_at DESC").ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	return man.queryAPIKeys(ctx, sql, args)
+}
+
+//ExpireAPIKey sets when the key stops being accepted, used to give callers a grace period during rotation
+func (man APIKeyManager) ExpireAPIKey(ctx context.Context, keyID string, expiresAt time.Time) error {
+	defer observeQuery("APIKeyManager", "ExpireAPIKey", time.Now())
+
+	return man.updateAPIKey(ctx, keyID, "expires_at", expiresAt)
+}
+
+//RevokeAPIKey stops the key from being accepted immediately
+func (man APIKeyManager) RevokeAPIKey(ctx context.Context, keyID string) error {
+	defer observeQuery("APIKeyManager", "RevokeAPIKey", time.Now())
+
+	return man.updateAPIKey(ctx, keyID, "revoked_at", time.Now())
+}
+
+func (man APIKeyManager) updateAPIKey(ctx context.Context, keyID string, column string, value time.Time) error {
+	if len(keyID) == 0 {
+		return errors.New("Invalid key ID")
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql
+	sql, args, err := psql.Update(apiKeysTableName).Set(column, value).Where(sq.Eq{"key_id": keyID}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	//execute
+	result, err := man.dbHelper.ExecContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return err
+	}
+
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return errors.New("API key not found")
+	}
+
+	return nil
+}
+
+func (man APIKeyManager) queryAPIKeys(ctx context.Context, sql string, args []interface{}) ([]*models.APIKey, error) {
+	//key hashes are not logged
+	man.logger.Debug("query", "sql", sql)
+
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key := &models.APIKey{}
+		err = rows.Scan(
+			&key.KeyID,
+			&key.ClientID,
+			&key.TenantID,
+			&key.Name,
+			&key.Prefix,
+			&key.Hash,
+			pq.Array(&key.Scopes),
+			&key.CreatedAt,
+			&key.ExpiresAt,
+			&key.RevokedAt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,29 @@
+package dataAccess
+
+import (
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+)
+
+//normalizedAddressColumns returns the normalized address column names for a column prefix such as "address_from", in the order of normalizedAddressValues
+func normalizedAddressColumns(prefix string) []string {
+	return []string{
+		prefix + "_city_norm",
+		prefix + "_state_norm",
+		prefix + "_zip_norm",
+		prefix + "_zip3",
+		prefix + "_country_norm",
+	}
+}
+
+//normalizedAddressValues returns the normalized address values, storing empty values as NULL
+func normalizedAddressValues(normalized integrations.NormalizedAddress) []interface{} {
+	var values []interface{}
+	for _, value := range []string{normalized.City, normalized.State, normalized.Zip, normalized.Zip3, normalized.Country} {
+		if len(value) == 0 {
+			values = append(values, nil)
+		} else {
+			values = append(values, value)
+		}
+	}
+	return values
+}
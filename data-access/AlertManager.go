@@ -0,0 +1,251 @@
+package dataAccess
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const (
+	alertsTableName = "alerts"
+)
+
+//AlertManager stores performance alerts. Unlike the shipment managers it is not tenant scoped, since the evaluator works across tenants.
+type AlertManager struct {
+	dbHelper *sql.DB
+	logger   *logging.Logger
+}
+
+//ListActiveTenants returns every tenant with tracking activity since the given time
+func (man AlertManager) ListActiveTenants(ctx context.Context, since time.Time) ([]string, error) {
+	defer observeQuery("AlertManager", "ListActiveTenants", time.Now())
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := psql.Select("DISTINCT tenant_id").From(trackingEventTableName).Where(sq.GtOrEq{"status_date": since}).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenantIDs []string
+	for rows.Next() {
+		var tenantID string
+		if err = rows.Scan(&tenantID); err != nil {
+			return nil, err
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tenantIDs, nil
+}
+
+//InsertAlert stores the alert and returns its alert ID
+func (man AlertManager) InsertAlert(ctx context.Context, alert *models.Alert) (string, error) {
+	defer observeQuery("AlertManager", "InsertAlert", time.Now())
+
+	if alert == nil {
+		return "", errors.New("nil alert")
+	}
+	if len(alert.TenantID) == 0 {
+		return "", errMissingTenant
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql
+	sql, args, err := psql.Insert(alertsTableName).
+		Columns(
+			"tenant_id",
+			"carrier",
+			"origin_state",
+			"destination_state",
+			"metric",
+			"baseline_value",
+			"recent_value",
+			"threshold_value",
+			"baseline_samples",
+			"recent_samples").
+		Values(
+			alert.TenantID,
+			alert.Carrier,
+			alert.OriginState,
+			alert.DestinationState,
+			alert.Metric,
+			alert.BaselineValue,
+			alert.RecentValue,
+			alert.ThresholdValue,
+			alert.BaselineSamples,
+			alert.RecentSamples).
+		Suffix("RETURNING alert_id, created_at").
+		ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	//execute
+	err = man.dbHelper.QueryRowContext(ctx, sql, args...).Scan(&alert.AlertID, &alert.CreatedAt)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return "", err
+	}
+
+	return alert.AlertID, nil
+}
+
+//GetLastAlertTime returns when an alert was last raised for the same tenant, carrier, lane and metric, or the zero time if never
+func (man AlertManager) GetLastAlertTime(ctx context.Context, alert *models.Alert) (time.Time, error) {
+	defer observeQuery("AlertManager", "GetLastAlertTime", time.Now())
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := psql.Select("MAX(created_at)").
+		From(alertsTableName).
+		Where(sq.Eq{
+			"tenant_id":         alert.TenantID,
+			"carrier":           alert.Carrier,
+			"origin_state":      alert.OriginState,
+			"destination_state": alert.DestinationState,
+			"metric":            alert.Metric,
+		}).
+		ToSql()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	var lastAlert *time.Time
+	err = man.dbHelper.QueryRowContext(ctx, sql, args...).Scan(&lastAlert)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return time.Time{}, err
+	}
+	if lastAlert == nil {
+		return time.Time{}, nil
+	}
+
+	return *lastAlert, nil
+}
+
+//RecordDelivery marks the alert as delivered, or records why delivery failed
+func (man AlertManager) RecordDelivery(ctx context.Context, alertID string, deliveryErr error) error {
+	defer observeQuery("AlertManager", "RecordDelivery", time.Now())
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	builder := psql.Update(alertsTableName).Where(sq.Eq{"alert_id": alertID})
+	if deliveryErr == nil {
+		builder = builder.Set("delivered_at", time.Now()).Set("delivery_error", nil)
+	} else {
+		builder = builder.Set("delivery_error", deliveryErr.Error())
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	_, err = man.dbHelper.ExecContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return err
+	}
+
+	return nil
+}
+
+//ListAlerts returns the tenant's most recent alerts
+func (man AlertManager) ListAlerts(ctx context.Context, tenantID string, limit int) ([]*models.Alert, error) {
+	defer observeQuery("AlertManager", "ListAlerts", time.Now())
+
+	if len(tenantID) == 0 {
+		return nil, errMissingTenant
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	sql, args, err := psql.Select(
+		"alert_id",
+		"tenant_id",
+		"carrier",
+		"origin_state",
+		"destination_state",
+		"metric",
+		"baseline_value",
+		"recent_value",
+		"threshold_value",
+		"baseline_samples",
+		"recent_samples",
+		"created_at",
+		"delivered_at",
+		"delivery_error").
+		From(alertsTableName).
+		Where(sq.Eq{"tenant_id": tenantID}).
+		OrderBy("created_at DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []*models.Alert{}
+	for rows.Next() {
+		alert := &models.Alert{}
+		err = rows.Scan(
+			&alert.AlertID,
+			&alert.TenantID,
+			&alert.Carrier,
+			&alert.OriginState,
+			&alert.DestinationState,
+			&alert.Metric,
+			&alert.BaselineValue,
+			&alert.RecentValue,
+			&alert.ThresholdValue,
+			&alert.BaselineSamples,
+			&alert.RecentSamples,
+			&alert.CreatedAt,
+			&alert.DeliveredAt,
+			&alert.DeliveryError)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,149 @@
+package dataAccess_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+)
+
+//fakeDriver connects while up is non-zero and records the statements executed and queried on its connections.
+//It is a driver.Connector, so tests open it with sql.OpenDB instead of registering it.
+type fakeDriver struct {
+	up int32
+
+	//duplicates is how many rows of each insert are reported as already existing
+	duplicates int
+	//returned is the single column every query returns, one row per value
+	returned []driver.Value
+
+	mu    sync.Mutex
+	execs []fakeExec
+}
+
+type fakeExec struct {
+	query string
+	args  []driver.NamedValue
+	rows  int
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	if atomic.LoadInt32(&d.up) == 0 {
+		return nil, errors.New("connection refused")
+	}
+	return &fakeConn{driver: d}, nil
+}
+
+func (d *fakeDriver) Connect(ctx context.Context) (driver.Conn, error) {
+	return d.Open("")
+}
+
+func (d *fakeDriver) Driver() driver.Driver {
+	return d
+}
+
+//statements returns the statements executed so far
+func (d *fakeDriver) statements() []fakeExec {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]fakeExec(nil), d.execs...)
+}
+
+//newFakeConnection returns a pooled connection on the fake database
+func newFakeConnection(t *testing.T, fake *fakeDriver) *dataAccess.SQLConnection {
+	pool := dataAccess.NewPool(func() (*sql.DB, error) {
+		return sql.OpenDB(fake), nil
+	}, dataAccess.DefaultPoolConfig)
+	t.Cleanup(func() { pool.Close() })
+
+	conn, err := pool.Connection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *fakeConn) Ping(ctx context.Context) error {
+	if atomic.LoadInt32(&c.driver.up) == 0 {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rows := insertedRows(query, len(args))
+
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.execs = append(c.driver.execs, fakeExec{query: query, args: args, rows: rows})
+
+	if affected := rows - c.driver.duplicates; affected > 0 {
+		return driver.RowsAffected(affected), nil
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.execs = append(c.driver.execs, fakeExec{query: query, args: args, rows: insertedRows(query, len(args))})
+
+	return &fakeRows{values: c.driver.returned}, nil
+}
+
+type fakeRows struct {
+	values []driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"value"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0] = r.values[0]
+	r.values = r.values[1:]
+	return nil
+}
+
+//insertedRows is how many rows an INSERT carries, its arguments divided by the columns it lists, or zero for other statements
+func insertedRows(query string, args int) int {
+	if !strings.HasPrefix(query, "INSERT") {
+		return 0
+	}
+	open := strings.Index(query, "(")
+	close := strings.Index(query, ")")
+	if open < 0 || close < open {
+		return 0
+	}
+	return args / (strings.Count(query[open:close], ",") + 1)
+}
@@ -0,0 +1,177 @@
+package dataAccess
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+	"github.com/lib/pq"
+)
+
+const (
+	ingestAuditTableName = "ingest_audit"
+)
+
+//IngestAuditManager appends to and reads the tenant's ingest audit trail. Entries are never updated or deleted.
+type IngestAuditManager struct {
+	dbHelper *sql.DB
+	logger   *logging.Logger
+	tenantID string
+}
+
+//InsertAuditEntry appends the entry and returns its audit ID
+func (man IngestAuditManager) InsertAuditEntry(ctx context.Context, entry *models.IngestAuditEntry) (string, error) {
+	defer observeQuery("IngestAuditManager", "InsertAuditEntry", time.Now())
+
+	if entry == nil {
+		return "", errors.New("nil audit entry")
+	}
+	if len(man.tenantID) == 0 {
+		return "", errMissingTenant
+	}
+
+	changedFields := entry.ChangedFields
+	if changedFields == nil {
+		changedFields = []string{}
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql
+	sql, args, err := psql.Insert(ingestAuditTableName).
+		Columns(
+			"tenant_id",
+			"shipment_id",
+			"client_id",
+			"key_id",
+			"request_id",
+			"carrier",
+			"tracking_code",
+			"outcome",
+			"upstream_status",
+			"new_events",
+			"changed_fields",
+			"error").
+		Values(
+			man.tenantID,
+			entry.ShipmentID,
+			entry.ClientID,
+			entry.KeyID,
+			entry.RequestID,
+			entry.Carrier,
+			entry.TrackingCode,
+			entry.Outcome,
+			entry.UpstreamStatus,
+			entry.NewEvents,
+			pq.Array(changedFields),
+			entry.Error).
+		Suffix("RETURNING audit_id, created_at").
+		ToSql()
+	if err != nil {
+		return "", err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	//execute
+	err = man.dbHelper.QueryRowContext(ctx, sql, args...).Scan(&entry.AuditID, &entry.CreatedAt)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return "", err
+	}
+	entry.TenantID = man.tenantID
+
+	return entry.AuditID, nil
+}
+
+//GetAuditTrail returns the newest entries for a shipment ID, or for a tracking code (optionally limited to a carrier) so attempts that never saved a shipment are included
+func (man IngestAuditManager) GetAuditTrail(ctx context.Context, shipmentID string, carrier string, trackingCode string, limit int) ([]*models.IngestAuditEntry, error) {
+	defer observeQuery("IngestAuditManager", "GetAuditTrail", time.Now())
+
+	if len(man.tenantID) == 0 {
+		return nil, errMissingTenant
+	}
+	if len(shipmentID) == 0 && len(trackingCode) == 0 {
+		return nil, errors.New("Shipment ID or tracking code is required")
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql
+	builder := psql.Select(
+		"audit_id",
+		"shipment_id",
+		"client_id",
+		"key_id",
+		"request_id",
+		"carrier",
+		"tracking_code",
+		"outcome",
+		"upstream_status",
+		"new_events",
+		"changed_fields",
+		"error",
+		"created_at").
+		From(ingestAuditTableName).
+		Where(sq.Eq{"tenant_id": man.tenantID})
+	if len(shipmentID) != 0 {
+		builder = builder.Where(sq.Eq{"shipment_id": shipmentID})
+	}
+	if len(trackingCode) != 0 {
+		builder = builder.Where(sq.Eq{"tracking_code": trackingCode})
+	}
+	if len(carrier) != 0 {
+		builder = builder.Where(sq.Eq{"carrier": carrier})
+	}
+
+	sql, args, err := builder.OrderBy("created_at DESC").Limit(uint64(limit)).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	//execute
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.IngestAuditEntry{}
+	for rows.Next() {
+		entry := &models.IngestAuditEntry{TenantID: man.tenantID}
+		err = rows.Scan(
+			&entry.AuditID,
+			&entry.ShipmentID,
+			&entry.ClientID,
+			&entry.KeyID,
+			&entry.RequestID,
+			&entry.Carrier,
+			&entry.TrackingCode,
+			&entry.Outcome,
+			&entry.UpstreamStatus,
+			&entry.NewEvents,
+			pq.Array(&entry.ChangedFields),
+			&entry.Error,
+			&entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if entry.ChangedFields == nil {
+			entry.ChangedFields = []string{}
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,14 @@
+package dataAccess
+
+import (
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/metrics"
+)
+
+var queryLatency = metrics.NewHistogram("db_query_duration_ms", "Database latency per manager method", metrics.UnitMilliseconds, metrics.LatencyBuckets, "manager", "method")
+
+//observeQuery records the latency of a manager method, deferred at its start
+func observeQuery(manager string, method string, start time.Time) {
+	queryLatency.ObserveSince(start, manager, method)
+}
@@ -0,0 +1,246 @@
+package dataAccess
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/metrics"
+)
+
+var poolConnects = metrics.NewCounter("db_pool_connects", "Database handles opened by the shared pool, initially or to reconnect after a failed health check", "reason")
+
+//PoolConfig tunes a connection pool
+type PoolConfig struct {
+	MaxOpenConns        int           //zero means unlimited
+	MaxIdleConns        int           //connections kept open between requests
+	ConnMaxLifetime     time.Duration //connections are recycled after this long, e.g. to follow a database failover
+	HealthCheckInterval time.Duration //the handle is pinged before use when it has not been checked for this long
+	PingTimeout         time.Duration
+}
+
+//DefaultPoolConfig keeps a few idle connections between requests, a Lambda container serves one request at a time
+var DefaultPoolConfig = PoolConfig{
+	MaxOpenConns:        10,
+	MaxIdleConns:        5,
+	ConnMaxLifetime:     30 * time.Minute,
+	HealthCheckInterval: 30 * time.Second,
+	PingTimeout:         2 * time.Second,
+}
+
+//PoolConfigFromEnv overrides DefaultPoolConfig with any of the DB_* variables that are set:
+//DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS as counts, DB_CONN_MAX_LIFETIME, DB_HEALTH_CHECK_INTERVAL and DB_PING_TIMEOUT as durations (e.g. 30s)
+func PoolConfigFromEnv(getenv func(string) string) (PoolConfig, error) {
+	config := DefaultPoolConfig
+
+	counts := []struct {
+		name  string
+		value *int
+	}{
+		{"DB_MAX_OPEN_CONNS", &config.MaxOpenConns},
+		{"DB_MAX_IDLE_CONNS", &config.MaxIdleConns},
+	}
+	for _, c := range counts {
+		if raw := getenv(c.name); len(raw) != 0 {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 0 {
+				return config, fmt.Errorf("Invalid %s %q", c.name, raw)
+			}
+			*c.value = parsed
+		}
+	}
+
+	durations := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"DB_CONN_MAX_LIFETIME", &config.ConnMaxLifetime},
+		{"DB_HEALTH_CHECK_INTERVAL", &config.HealthCheckInterval},
+		{"DB_PING_TIMEOUT", &config.PingTimeout},
+	}
+	for _, d := range durations {
+		if raw := getenv(d.name); len(raw) != 0 {
+			parsed, err := time.ParseDuration(raw)
+			if err != nil || parsed < 0 {
+				return config, fmt.Errorf("Invalid %s %q", d.name, raw)
+			}
+			*d.value = parsed
+		}
+	}
+
+	if config.PingTimeout == 0 {
+		return config, fmt.Errorf("DB_PING_TIMEOUT must be positive")
+	}
+	return config, nil
+}
+
+//Pool lazily opens a database handle and hands out connections sharing it, so warm Lambda invocations reuse open connections
+//instead of paying for TCP and authentication on every request. The handle is pinged before use when it has not been checked
+//within the health check interval, and replaced if the ping fails.
+type Pool struct {
+	open   func() (*sql.DB, error)
+	config PoolConfig
+
+	mu        sync.Mutex
+	db        *sql.DB
+	checkedAt time.Time
+	checking  bool //a caller is pinging the handle, others use it meanwhile
+}
+
+//retiredHandleGrace is how long a replaced handle stays open for queries already running on it, longer than any request's deadline
+const retiredHandleGrace = time.Minute
+
+//NewPool creates a pool that opens its handle with open on first use
+func NewPool(open func() (*sql.DB, error), config PoolConfig) *Pool {
+	return &Pool{
+		open:   open,
+		config: config,
+	}
+}
+
+//Connection returns a connection on the pool's handle, opening or replacing it as needed. Errors mean the database is unreachable.
+//Connections from a pool are never destroyed, the pool keeps them open for the next request.
+//Health checks run on their own timeout rather than the caller's context, so a request that is out of time cannot condemn a healthy handle.
+func (pool *Pool) Connection(ctx context.Context) (*SQLConnection, error) {
+	log := logging.FromContext(ctx)
+
+	db, err := pool.handle(log)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLConnection{
+		dbHelper: db,
+		logger:   log,
+		pooled:   true,
+	}, nil
+}
+
+//handle returns the current handle, checking its health first when that is due
+func (pool *Pool) handle(log *logging.Logger) (*sql.DB, error) {
+	pool.mu.Lock()
+	db := pool.db
+	checkDue := db != nil && !pool.checking && time.Since(pool.checkedAt) >= pool.config.HealthCheckInterval
+	if checkDue {
+		pool.checking = true
+	}
+	pool.mu.Unlock()
+
+	if db == nil {
+		return pool.replace(nil, "initial")
+	}
+	if !checkDue {
+		return db, nil
+	}
+
+	//ping without holding the lock, concurrent requests keep using the handle
+	err := pool.ping(db)
+	pool.mu.Lock()
+	pool.checking = false
+	if err == nil {
+		pool.checkedAt = time.Now()
+	}
+	pool.mu.Unlock()
+
+	switch {
+	case err == nil:
+		return db, nil
+	case errors.Is(err, context.DeadlineExceeded):
+		//a slow database is not a broken handle, check again on the next request
+		log.Warn("database health check timed out", logging.KeyError, err)
+		return db, nil
+	}
+
+	log.Warn("database health check failed, reconnecting", logging.KeyError, err)
+	return pool.replace(db, "reconnect")
+}
+
+//replace opens a handle to take the place of stale, nil for the first, unless another caller replaced it already.
+//The lock is held while connecting so only one replacement is opened. The stale handle is retired rather than closed
+//under requests still using it, and kept if the replacement cannot connect.
+func (pool *Pool) replace(stale *sql.DB, reason string) (*sql.DB, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.db != stale {
+		return pool.db, nil
+	}
+
+	poolConnects.Inc(reason)
+	db, err := pool.open()
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(pool.config.MaxOpenConns)
+	db.SetMaxIdleConns(pool.config.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.config.ConnMaxLifetime)
+
+	//sql.Open does not connect, check now so callers can fail fast
+	if err := pool.ping(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	pool.db = db
+	pool.checkedAt = time.Now()
+	if stale != nil {
+		retire(stale)
+	}
+	return db, nil
+}
+
+func (pool *Pool) ping(db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pool.config.PingTimeout)
+	defer cancel()
+
+	return db.PingContext(ctx)
+}
+
+//retire stops a replaced handle keeping idle connections and closes it once queries still running on it have had time to finish
+func retire(db *sql.DB) {
+	db.SetMaxIdleConns(0)
+	time.AfterFunc(retiredHandleGrace, func() {
+		db.Close()
+	})
+}
+
+//Close closes the pool's handle, the next connection reopens it
+func (pool *Pool) Close() error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.db == nil {
+		return nil
+	}
+	err := pool.db.Close()
+	pool.db = nil
+	return err
+}
+
+var (
+	sharedPool     *Pool
+	sharedPoolErr  error
+	sharedPoolOnce sync.Once
+)
+
+//SharedConnection returns a connection from the process-wide pool, configured from the environment by PoolConfigFromEnv on first use
+func SharedConnection(ctx context.Context) (*SQLConnection, error) {
+	sharedPoolOnce.Do(func() {
+		config, err := PoolConfigFromEnv(os.Getenv)
+		if err != nil {
+			sharedPoolErr = err
+			return
+		}
+		sharedPool = NewPool(openPostgres, config)
+	})
+	if sharedPoolErr != nil {
+		return nil, sharedPoolErr
+	}
+	return sharedPool.Connection(ctx)
+}
@@ -0,0 +1,152 @@
+package dataAccess_test
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+)
+
+var testDriver = &fakeDriver{up: 1}
+
+func newTestPool(healthCheckInterval time.Duration) (*dataAccess.Pool, *int) {
+	opens := 0
+	config := dataAccess.DefaultPoolConfig
+	config.HealthCheckInterval = healthCheckInterval
+
+	pool := dataAccess.NewPool(func() (*sql.DB, error) {
+		opens++
+		return sql.OpenDB(testDriver), nil
+	}, config)
+	return pool, &opens
+}
+
+func TestPoolReuse(t *testing.T) {
+	atomic.StoreInt32(&testDriver.up, 1)
+	pool, opens := newTestPool(time.Hour)
+	defer pool.Close()
+
+	for i := 0; i < 3; i++ {
+		conn, err := pool.Connection(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		//pooled connections survive being destroyed
+		conn.Destroy()
+	}
+	if *opens != 1 {
+		t.Errorf("expected the handle to be opened once, opened %d times", *opens)
+	}
+}
+
+func TestPoolReconnect(t *testing.T) {
+	atomic.StoreInt32(&testDriver.up, 1)
+	pool, opens := newTestPool(0)
+	defer pool.Close()
+
+	conn, err := pool.Connection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Destroy()
+
+	//a healthy handle is checked and kept
+	if _, err := pool.Connection(context.Background()); err != nil || *opens != 1 {
+		t.Fatalf("healthy handle not reused: %v, %d opens", err, *opens)
+	}
+
+	//a failed health check reopens the handle, which fails while the database is down
+	atomic.StoreInt32(&testDriver.up, 0)
+	if _, err := pool.Connection(context.Background()); err == nil {
+		t.Error("expected an error while the database is down")
+	}
+	if *opens != 2 {
+		t.Errorf("expected a reconnect attempt, opened %d times", *opens)
+	}
+
+	//the failed replacement left the old handle in place, which works again once the database is back
+	atomic.StoreInt32(&testDriver.up, 1)
+	if _, err := pool.Connection(context.Background()); err != nil {
+		t.Errorf("pool did not recover: %v", err)
+	}
+	if *opens != 2 {
+		t.Errorf("expected the old handle to be kept, opened %d times", *opens)
+	}
+}
+
+func TestPoolReplacesBrokenHandle(t *testing.T) {
+	var handles []*fakeDriver
+	config := dataAccess.DefaultPoolConfig
+	config.HealthCheckInterval = 0
+	pool := dataAccess.NewPool(func() (*sql.DB, error) {
+		handle := &fakeDriver{up: 1}
+		handles = append(handles, handle)
+		return sql.OpenDB(handle), nil
+	}, config)
+	defer pool.Close()
+
+	if _, err := pool.Connection(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	//the first handle breaks, a replacement is swapped in and used from then on
+	atomic.StoreInt32(&handles[0].up, 0)
+	conn, err := pool.Connection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(handles) != 2 {
+		t.Fatalf("expected a replacement handle, opened %d", len(handles))
+	}
+	if _, err := conn.TrackingEventManager("tenant-1").InsertTrackingEvents(context.Background(), []*integrations.TrackingEvent{{EventID: "1"}}, "shipment-1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(handles[1].statements()) != 1 {
+		t.Error("expected the connection to use the replacement handle")
+	}
+}
+
+func TestPoolIgnoresCallerContext(t *testing.T) {
+	atomic.StoreInt32(&testDriver.up, 1)
+	pool, opens := newTestPool(0)
+	defer pool.Close()
+
+	if _, err := pool.Connection(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	//a request that has run out of time still gets the healthy handle, which is kept
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pool.Connection(ctx); err != nil {
+		t.Errorf("health check failed on the caller's context: %v", err)
+	}
+	if *opens != 1 {
+		t.Errorf("expected the healthy handle to be kept, opened %d times", *opens)
+	}
+}
+
+func TestPoolConfigFromEnv(t *testing.T) {
+	env := map[string]string{
+		"DB_MAX_OPEN_CONNS":        "4",
+		"DB_HEALTH_CHECK_INTERVAL": "1m",
+	}
+	config, err := dataAccess.PoolConfigFromEnv(func(name string) string {
+		return env[name]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.MaxOpenConns != 4 || config.HealthCheckInterval != time.Minute || config.MaxIdleConns != dataAccess.DefaultPoolConfig.MaxIdleConns {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	env["DB_MAX_IDLE_CONNS"] = "-1"
+	if _, err := dataAccess.PoolConfigFromEnv(func(name string) string { return env[name] }); err == nil {
+		t.Error("expected an error for a negative count")
+	}
+}
@@ -3,6 +3,8 @@ package dataAccess
 import (
 	"database/sql"
 	"fmt"
+
+	"github.com/elorusso/wonderment-tech-eval/logging"
 )
 
 const (
@@ -15,35 +17,84 @@ const (
 
 type SQLConnection struct {
 	dbHelper *sql.DB
+	logger   *logging.Logger
+	pooled   bool
 }
 
+//NewSQLConnection opens a connection of its own, for one-off tools. Request handlers should use SharedConnection.
 func NewSQLConnection() (*SQLConnection, error) {
-	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s "+
-		"password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
-
-	db, err := sql.Open("postgres", psqlInfo)
+	db, err := openPostgres()
 	if err != nil {
 		return nil, err
 	}
 
 	return &SQLConnection{
 		dbHelper: db,
+		logger:   logging.Default(),
 	}, nil
 }
 
+func openPostgres() (*sql.DB, error) {
+	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s "+
+		"password=%s dbname=%s sslmode=disable",
+		host, port, user, password, dbname)
+
+	return sql.Open("postgres", psqlInfo)
+}
+
+//Destroy closes a connection from NewSQLConnection. Connections from a Pool are left open for the next request.
 func (conn *SQLConnection) Destroy() {
+	if conn.pooled {
+		return
+	}
 	conn.dbHelper.Close()
 }
 
-func (conn SQLConnection) ShipmentManager() *ShipmentsManager {
+//WithLogger returns a connection sharing this one's database handle whose managers log to the logger, e.g. one tagged with the request ID.
+//Only the original connection should be destroyed.
+func (conn SQLConnection) WithLogger(logger *logging.Logger) *SQLConnection {
+	conn.logger = logger
+	return &conn
+}
+
+//ShipmentManager returns a manager that only reads and writes the tenant's shipments
+func (conn SQLConnection) ShipmentManager(tenantID string) *ShipmentsManager {
 	return &ShipmentsManager{
 		dbHelper: conn.dbHelper,
+		logger:   conn.logger,
+		tenantID: tenantID,
 	}
 }
 
-func (conn SQLConnection) TrackingEventManager() *TrackingEventManager {
+//TrackingEventManager returns a manager that only reads and writes the tenant's tracking events
+func (conn SQLConnection) TrackingEventManager(tenantID string) *TrackingEventManager {
 	return &TrackingEventManager{
 		dbHelper: conn.dbHelper,
+		logger:   conn.logger,
+		tenantID: tenantID,
+	}
+}
+
+func (conn SQLConnection) APIKeyManager() *APIKeyManager {
+	return &APIKeyManager{
+		dbHelper: conn.dbHelper,
+		logger:   conn.logger,
+	}
+}
+
+//AlertManager returns a manager for alert history across all tenants
+func (conn SQLConnection) AlertManager() *AlertManager {
+	return &AlertManager{
+		dbHelper: conn.dbHelper,
+		logger:   conn.logger,
+	}
+}
+
+//IngestAuditManager returns a manager for the tenant's ingest audit trail
+func (conn SQLConnection) IngestAuditManager(tenantID string) *IngestAuditManager {
+	return &IngestAuditManager{
+		dbHelper: conn.dbHelper,
+		logger:   conn.logger,
+		tenantID: tenantID,
 	}
 }
@@ -1,13 +1,19 @@
 package dataAccess
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/elorusso/wonderment-tech-eval/geo"
 	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
 	_ "github.com/lib/pq"
 )
 
@@ -15,15 +21,24 @@ const (
 	shipmentsTableName = "shipments"
 )
 
+var errMissingTenant = errors.New("Tenant ID is required")
+
 type ShipmentsManager struct {
 	dbHelper *sql.DB
+	logger   *logging.Logger
+	tenantID string
 }
 
-//InsertShipment creates a new shipment in the database and returns the shipment ID. If the shipment already exisits, the existing shipment ID is returned.
-func (man ShipmentsManager) InsertShipment(shipment *integrations.WondermentShipment) (string, error) {
+//InsertShipment creates a new shipment in the database and returns the shipment ID. If the shipment already exisits, its service level, ETAs, test flag, normalized addresses and lane are refreshed and the existing shipment ID is returned.
+func (man ShipmentsManager) InsertShipment(ctx context.Context, shipment *integrations.WondermentShipment) (string, error) {
+	defer observeQuery("ShipmentsManager", "InsertShipment", time.Now())
+
 	if shipment == nil {
 		return "", errors.New("nil shipment")
 	}
+	if len(man.tenantID) == 0 {
+		return "", errMissingTenant
+	}
 
 	var etaString *string
 	if !shipment.ETA.IsZero() {
@@ -48,14 +63,44 @@ func (man ShipmentsManager) InsertShipment(shipment *integrations.WondermentShip
 		shipment.ServiceLevel = &integrations.ServiceLevel{}
 	}
 
+	//normalize service level so carriers can be compared within a speed class
+	var speedClass, minTransitDays, maxTransitDays interface{}
+	if serviceLevelInfo, ok := integrations.ClassifyServiceLevel(shipment.Carrier, shipment.ServiceLevel); ok {
+		speedClass = string(serviceLevelInfo.SpeedClass)
+		minTransitDays = serviceLevelInfo.MinTransitDays
+		maxTransitDays = serviceLevelInfo.MaxTransitDays
+	}
+
+	//raw addresses are kept as sent, normalized copies and the lane are stored alongside for lane analytics
+	columns, values := shipmentAddressColumns(shipment.AddressFrom, shipment.AddressTo)
+
+	//refresh what upstream may revise, normalized addresses and lane included, and make sure we get a shipment ID back even on conflict
+	updates := append([]string{
+		"carrier",
+		"service_level_name",
+		"service_level_token",
+		"speed_class",
+		"expected_transit_days_min",
+		"expected_transit_days_max",
+		"test",
+		"eta",
+		"original_eta"}, columns...)
+	for i, column := range updates {
+		updates[i] = column + "=EXCLUDED." + column
+	}
+
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	//build sql and execute
-	sql, args, err := psql.Insert(shipmentsTableName).Columns(
+	sql, args, err := psql.Insert(shipmentsTableName).Columns(append([]string{
+		"tenant_id",
 		"tracking_number",
 		"carrier",
 		"service_level_name",
 		"service_level_token",
+		"speed_class",
+		"expected_transit_days_min",
+		"expected_transit_days_max",
 		"address_from_city",
 		"address_from_state",
 		"address_from_zip",
@@ -66,12 +111,16 @@ func (man ShipmentsManager) InsertShipment(shipment *integrations.WondermentShip
 		"address_to_country",
 		"test",
 		"eta",
-		"original_eta").
-		Values(
+		"original_eta"}, columns...)...).
+		Values(append([]interface{}{
+			man.tenantID,
 			shipment.TrackingNumber,
 			shipment.Carrier,
 			shipment.ServiceLevel.Name,
 			shipment.ServiceLevel.Token,
+			speedClass,
+			minTransitDays,
+			maxTransitDays,
 			shipment.AddressFrom.City,
 			shipment.AddressFrom.State,
 			shipment.AddressFrom.Zip,
@@ -82,19 +131,18 @@ func (man ShipmentsManager) InsertShipment(shipment *integrations.WondermentShip
 			shipment.AddressTo.Country,
 			shipment.Test,
 			etaString,
-			originalETAString).
-		Suffix(
-			"ON CONFLICT (carrier, tracking_number) DO UPDATE SET carrier=EXCLUDED.carrier RETURNING shipment_id"). //make sure we get a shipment ID back even on conflict
+			originalETAString}, values...)...).
+		Suffix("ON CONFLICT (tenant_id, carrier, tracking_number) DO UPDATE SET " + strings.Join(updates, ", ") + " RETURNING shipment_id").
 		ToSql()
 	if err != nil {
 		return "", err
 	}
 
-	fmt.Println(sql, args)
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
 
-	rows, err := man.dbHelper.Query(sql, args...)
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
 	if err != nil {
-		fmt.Println(err)
+		man.logger.Error("query failed", logging.KeyError, err)
 		return "", err
 	}
 	defer rows.Close()
@@ -115,25 +163,208 @@ func (man ShipmentsManager) InsertShipment(shipment *integrations.WondermentShip
 	return shipmentID, nil
 }
 
-func (man ShipmentsManager) UpdateTransitTimeForShipment(shipmentID string, transitTime int) error {
+//shipmentAddressColumns returns the shipment's normalized address and lane columns and their values
+func shipmentAddressColumns(from *integrations.Address, to *integrations.Address) ([]string, []interface{}) {
+	addressFrom := integrations.NormalizeAddress(from)
+	addressTo := integrations.NormalizeAddress(to)
+	columns := append(normalizedAddressColumns("address_from"), normalizedAddressColumns("address_to")...)
+	values := append(normalizedAddressValues(addressFrom), normalizedAddressValues(addressTo)...)
+
+	//distance and zone between origin and destination, when both ZIP3s are known
+	columns = append(columns, "distance_miles", "zone")
+	if lane, ok := geo.LaneBetweenZip3s(addressFrom.Zip3, addressTo.Zip3); ok {
+		values = append(values, math.Round(lane.DistanceMiles*10)/10, lane.Zone)
+	} else {
+		values = append(values, nil, nil)
+	}
+	return columns, values
+}
+
+//ShipmentAddresses are a shipment's addresses as sent upstream, read back to recompute the normalized columns
+type ShipmentAddresses struct {
+	ShipmentID string
+	From       integrations.Address
+	To         integrations.Address
+}
+
+//ListShipmentAddresses returns up to limit of the tenant's shipments ordered by shipment ID, starting after the given shipment ID unless it is empty
+func (man ShipmentsManager) ListShipmentAddresses(ctx context.Context, afterShipmentID string, limit int) ([]*ShipmentAddresses, error) {
+	defer observeQuery("ShipmentsManager", "ListShipmentAddresses", time.Now())
+
+	if len(man.tenantID) == 0 {
+		return nil, errMissingTenant
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql
+	builder := psql.Select(
+		"shipment_id",
+		"address_from_city",
+		"address_from_state",
+		"address_from_zip",
+		"address_from_country",
+		"address_to_city",
+		"address_to_state",
+		"address_to_zip",
+		"address_to_country").
+		From(shipmentsTableName).
+		Where(sq.Eq{"tenant_id": man.tenantID})
+	if len(afterShipmentID) > 0 {
+		builder = builder.Where(sq.Gt{"shipment_id": afterShipmentID})
+	}
+	sql, args, err := builder.OrderBy("shipment_id").Limit(uint64(limit)).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shipments []*ShipmentAddresses
+	for rows.Next() {
+		shipment := &ShipmentAddresses{}
+		err = rows.Scan(
+			&shipment.ShipmentID,
+			&shipment.From.City,
+			&shipment.From.State,
+			&shipment.From.Zip,
+			&shipment.From.Country,
+			&shipment.To.City,
+			&shipment.To.State,
+			&shipment.To.Zip,
+			&shipment.To.Country)
+		if err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, shipment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return shipments, nil
+}
+
+//UpdateNormalizedAddresses recomputes the shipment's normalized address and lane columns from its addresses as sent
+func (man ShipmentsManager) UpdateNormalizedAddresses(ctx context.Context, shipment *ShipmentAddresses) error {
+	defer observeQuery("ShipmentsManager", "UpdateNormalizedAddresses", time.Now())
+
+	if shipment == nil || len(shipment.ShipmentID) == 0 {
+		return errors.New("Invalid shipment ID")
+	}
+	if len(man.tenantID) == 0 {
+		return errMissingTenant
+	}
+
+	columns, values := shipmentAddressColumns(&shipment.From, &shipment.To)
+	update := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Update(shipmentsTableName)
+	for i, column := range columns {
+		update = update.Set(column, values[i])
+	}
+
+	//build sql
+	sql, args, err := update.Where(sq.Eq{"shipment_id": shipment.ShipmentID, "tenant_id": man.tenantID}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	if _, err := man.dbHelper.ExecContext(ctx, sql, args...); err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return err
+	}
+
+	return nil
+}
+
+//GetShipmentSnapshot returns the stored fields InsertShipment refreshes on re-ingest, or nil if the shipment has not been saved
+func (man ShipmentsManager) GetShipmentSnapshot(ctx context.Context, carrier string, trackingNumber string) (*models.ShipmentSnapshot, error) {
+	defer observeQuery("ShipmentsManager", "GetShipmentSnapshot", time.Now())
+
+	if len(man.tenantID) == 0 {
+		return nil, errMissingTenant
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql, ETAs are cast so text and timestamp columns scan alike
+	sql, args, err := psql.Select(
+		"shipment_id",
+		"COALESCE(service_level_token, '')",
+		"COALESCE(speed_class, '')",
+		"eta::timestamptz",
+		"original_eta::timestamptz",
+		"COALESCE(test, false)").
+		From(shipmentsTableName).
+		Where(sq.Eq{"tenant_id": man.tenantID, "carrier": carrier, "tracking_number": trackingNumber}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	//execute
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	snapshot := &models.ShipmentSnapshot{}
+	err = rows.Scan(
+		&snapshot.ShipmentID,
+		&snapshot.ServiceLevelToken,
+		&snapshot.SpeedClass,
+		&snapshot.ETA,
+		&snapshot.OriginalETA,
+		&snapshot.Test)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+//UpdateTransitTimeForShipment records the time in transit, in milliseconds, and when the shipment was delivered
+func (man ShipmentsManager) UpdateTransitTimeForShipment(ctx context.Context, shipmentID string, transitTime int, deliveredAt time.Time) error {
+	defer observeQuery("ShipmentsManager", "UpdateTransitTimeForShipment", time.Now())
+
 	if len(shipmentID) == 0 {
 		return errors.New("Invalid shipment ID")
 	}
+	if len(man.tenantID) == 0 {
+		return errMissingTenant
+	}
 
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	//build sql
-	sql, args, err := psql.Update(shipmentsTableName).Set("time_in_transit", transitTime).Where(sq.Eq{"shipment_id": shipmentID}).ToSql()
+	sql, args, err := psql.Update(shipmentsTableName).Set("time_in_transit", transitTime).Set("delivered_at", deliveredAt).Where(sq.Eq{"shipment_id": shipmentID, "tenant_id": man.tenantID}).ToSql()
 	if err != nil {
-		fmt.Println(err)
+		man.logger.Error("query failed", logging.KeyError, err)
 		return err
 	}
 
-	fmt.Println(sql, args)
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
 
-	rows, err := man.dbHelper.Query(sql, args...)
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
 	if err != nil {
-		fmt.Println(err)
+		man.logger.Error("query failed", logging.KeyError, err)
 		return err
 	}
 	defer rows.Close()
@@ -141,27 +372,37 @@ func (man ShipmentsManager) UpdateTransitTimeForShipment(shipmentID string, tran
 	return nil
 }
 
-func (man ShipmentsManager) GetAverageTimeInTransit(carrier string) (int, error) {
+//GetAverageTimeInTransit returns the average time in transit in milliseconds, optionally filtered by carrier and speed class
+func (man ShipmentsManager) GetAverageTimeInTransit(ctx context.Context, carrier string, speedClass integrations.SpeedClass) (int, error) {
+	defer observeQuery("ShipmentsManager", "GetAverageTimeInTransit", time.Now())
+
+	if len(man.tenantID) == 0 {
+		return 0, errMissingTenant
+	}
+
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	//build sql query
-	builder := psql.Select("ROUND(AVG(time_in_transit))").From(shipmentsTableName)
+	builder := psql.Select("ROUND(AVG(time_in_transit))").From(shipmentsTableName).Where(sq.Eq{"tenant_id": man.tenantID})
 
 	if len(carrier) != 0 {
 		builder = builder.Where(sq.Eq{"carrier": carrier})
 	}
+	if len(speedClass) != 0 {
+		builder = builder.Where(sq.Eq{"speed_class": string(speedClass)})
+	}
 
 	sql, args, err := builder.ToSql()
 	if err != nil {
 		return 0, err
 	}
 
-	fmt.Println(sql, args)
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
 
 	//execute
-	rows, err := man.dbHelper.Query(sql, args...)
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
 	if err != nil {
-		fmt.Println(err)
+		man.logger.Error("query failed", logging.KeyError, err)
 		return 0, err
 	}
 	defer rows.Close()
@@ -180,3 +421,398 @@ func (man ShipmentsManager) GetAverageTimeInTransit(carrier string) (int, error)
 
 	return averageTimeInTransit, nil
 }
+
+//TransitGrouping selects how GetAverageTimeInTransitByGroup buckets shipments
+type TransitGrouping string
+
+const (
+	GroupByZone         TransitGrouping = "zone"
+	GroupByDistanceBand TransitGrouping = "distance_band"
+)
+
+//GetAverageTimeInTransitByGroup returns the average time in transit per zone or distance band, optionally filtered by carrier and speed class.
+//Shipments without a known lane are left out.
+func (man ShipmentsManager) GetAverageTimeInTransitByGroup(ctx context.Context, carrier string, speedClass integrations.SpeedClass, groupBy TransitGrouping) ([]*models.TransitTimeGroup, error) {
+	defer observeQuery("ShipmentsManager", "GetAverageTimeInTransitByGroup", time.Now())
+
+	if len(man.tenantID) == 0 {
+		return nil, errMissingTenant
+	}
+
+	var groupExpression string
+	switch groupBy {
+	case GroupByZone:
+		groupExpression = "zone::text"
+	case GroupByDistanceBand:
+		groupExpression = distanceBandExpression()
+	default:
+		return nil, fmt.Errorf("Unsupported grouping %q", groupBy)
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql query
+	builder := psql.Select(groupExpression+" AS transit_group", "ROUND(AVG(time_in_transit))", "COUNT(*)").
+		From(shipmentsTableName).
+		Where(sq.Eq{"tenant_id": man.tenantID}).
+		Where(sq.NotEq{"time_in_transit": nil}).
+		Where(sq.NotEq{"distance_miles": nil}).
+		GroupBy("transit_group").
+		OrderBy("MIN(distance_miles)")
+
+	if len(carrier) != 0 {
+		builder = builder.Where(sq.Eq{"carrier": carrier})
+	}
+	if len(speedClass) != 0 {
+		builder = builder.Where(sq.Eq{"speed_class": string(speedClass)})
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	//execute
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []*models.TransitTimeGroup{}
+	for rows.Next() {
+		group := &models.TransitTimeGroup{}
+		err = rows.Scan(&group.Group, &group.AverageTimeInTransit, &group.Count)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+//distanceBandExpression labels distance_miles with its geo.DistanceBands label
+func distanceBandExpression() string {
+	var builder strings.Builder
+	builder.WriteString("CASE")
+	for _, band := range geo.DistanceBands {
+		if band.MaxMiles == 0 {
+			builder.WriteString(fmt.Sprintf(" ELSE '%s'", band.Label))
+		} else {
+			builder.WriteString(fmt.Sprintf(" WHEN distance_miles < %g THEN '%s'", band.MaxMiles, band.Label))
+		}
+	}
+	builder.WriteString(" END")
+	return builder.String()
+}
+
+//transitFilterWhere applies the filter to a query over the shipments table
+func transitFilterWhere(builder sq.SelectBuilder, filter models.TransitFilter) sq.SelectBuilder {
+	if len(filter.Carrier) != 0 {
+		builder = builder.Where(sq.Eq{"carrier": filter.Carrier})
+	}
+	if len(filter.ServiceLevelToken) != 0 {
+		builder = builder.Where(sq.Eq{"service_level_token": filter.ServiceLevelToken})
+	}
+	if len(filter.SpeedClass) != 0 {
+		builder = builder.Where(sq.Eq{"speed_class": filter.SpeedClass})
+	}
+	if len(filter.OriginZip3) != 0 {
+		builder = builder.Where(sq.Eq{"address_from_zip3": filter.OriginZip3})
+	}
+	if len(filter.DestinationZip3) != 0 {
+		builder = builder.Where(sq.Eq{"address_to_zip3": filter.DestinationZip3})
+	}
+	if len(filter.OriginState) != 0 {
+		builder = builder.Where(sq.Eq{"address_from_state_norm": filter.OriginState})
+	}
+	if len(filter.DestinationState) != 0 {
+		builder = builder.Where(sq.Eq{"address_to_state_norm": filter.DestinationState})
+	}
+	if filter.Zone != 0 {
+		builder = builder.Where(sq.Eq{"zone": filter.Zone})
+	}
+	return builder
+}
+
+//GetTransitTimes returns up to limit times in transit, in milliseconds, of the most recently delivered shipments matching the filter
+func (man ShipmentsManager) GetTransitTimes(ctx context.Context, filter models.TransitFilter, limit int) ([]int, error) {
+	defer observeQuery("ShipmentsManager", "GetTransitTimes", time.Now())
+
+	if len(man.tenantID) == 0 {
+		return nil, errMissingTenant
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql query
+	builder := psql.Select("time_in_transit").
+		From(shipmentsTableName).
+		Where(sq.Eq{"tenant_id": man.tenantID}).
+		Where(sq.NotEq{"time_in_transit": nil}).
+		OrderBy("delivered_at DESC NULLS LAST").
+		Limit(uint64(limit))
+	builder = transitFilterWhere(builder, filter)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	//execute
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitTimes []int
+	for rows.Next() {
+		var transitTime int
+		err = rows.Scan(&transitTime)
+		if err != nil {
+			return nil, err
+		}
+		transitTimes = append(transitTimes, transitTime)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transitTimes, nil
+}
+
+const (
+	//onTimeExpression is true when a delivered shipment arrived by its original ETA, falling back to the service level's promised transit days, and NULL when neither is known
+	onTimeExpression = `CASE
+		WHEN s.delivered_at IS NULL THEN NULL
+		WHEN s.original_eta IS NOT NULL THEN s.delivered_at::date <= s.original_eta::date
+		WHEN s.eta IS NOT NULL THEN s.delivered_at::date <= s.eta::date
+		WHEN s.expected_transit_days_max IS NOT NULL THEN s.time_in_transit <= s.expected_transit_days_max * 86400000
+		ELSE NULL END`
+
+	//exceptionExpression is true when any tracking event of the shipment reports a failure, return or required action
+	exceptionExpression = `EXISTS (SELECT 1 FROM tracking_events e
+		WHERE e.tenant_id = s.tenant_id AND e.shipment_id = s.shipment_id
+		AND (LOWER(e.status) IN ('failure', 'returned') OR e.substatus_action_required))`
+)
+
+//GetCarrierStats returns performance statistics per carrier and service level for the shipments matching the filter
+func (man ShipmentsManager) GetCarrierStats(ctx context.Context, filter models.TransitFilter) ([]*models.CarrierStats, error) {
+	defer observeQuery("ShipmentsManager", "GetCarrierStats", time.Now())
+
+	if len(man.tenantID) == 0 {
+		return nil, errMissingTenant
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql query
+	builder := psql.Select(
+		"s.carrier",
+		"COALESCE(s.service_level_token, '')",
+		"COALESCE(MAX(s.speed_class), '')",
+		"COUNT(*)",
+		"COUNT(s.time_in_transit)",
+		"COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.time_in_transit), 0) / 3600000",
+		"COALESCE(PERCENTILE_CONT(0.9) WITHIN GROUP (ORDER BY s.time_in_transit), 0) / 3600000",
+		"COALESCE(AVG(CASE WHEN "+onTimeExpression+" THEN 1.0 ELSE 0.0 END) FILTER (WHERE ("+onTimeExpression+") IS NOT NULL), 0)",
+		"AVG(CASE WHEN "+exceptionExpression+" THEN 1.0 ELSE 0.0 END)").
+		From(shipmentsTableName+" s").
+		Where(sq.Eq{"s.tenant_id": man.tenantID}).
+		GroupBy("s.carrier", "s.service_level_token")
+	builder = transitFilterWhere(builder, filter)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	//execute
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*models.CarrierStats{}
+	for rows.Next() {
+		stat := &models.CarrierStats{}
+		err = rows.Scan(
+			&stat.Carrier,
+			&stat.ServiceLevelToken,
+			&stat.SpeedClass,
+			&stat.SampleCount,
+			&stat.DeliveredCount,
+			&stat.MedianTransitHours,
+			&stat.P90TransitHours,
+			&stat.OnTimeRate,
+			&stat.ExceptionRate)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+//GetTransitTrend returns transit time aggregates per carrier and time bucket for shipments delivered in [start, end).
+//Interval is a Postgres date_trunc unit (day, week or month) and buckets are aligned in UTC. Empty buckets are not returned.
+func (man ShipmentsManager) GetTransitTrend(ctx context.Context, filter models.TransitFilter, interval string, start time.Time, end time.Time) ([]*models.TrendPoint, error) {
+	defer observeQuery("ShipmentsManager", "GetTransitTrend", time.Now())
+
+	if len(man.tenantID) == 0 {
+		return nil, errMissingTenant
+	}
+	if interval != "day" && interval != "week" && interval != "month" {
+		return nil, fmt.Errorf("Unsupported interval %q", interval)
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql query, the interval is validated above so it is safe to inline
+	bucketExpression := fmt.Sprintf("date_trunc('%s', delivered_at AT TIME ZONE 'UTC')", interval)
+	builder := psql.Select(
+		bucketExpression+" AS bucket",
+		"carrier",
+		"COUNT(*)",
+		"AVG(time_in_transit) / 3600000",
+		"PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY time_in_transit) / 3600000").
+		From(shipmentsTableName).
+		Where(sq.Eq{"tenant_id": man.tenantID}).
+		Where(sq.NotEq{"time_in_transit": nil}).
+		Where(sq.GtOrEq{"delivered_at": start}).
+		Where(sq.Lt{"delivered_at": end}).
+		GroupBy("bucket", "carrier").
+		OrderBy("carrier", "bucket")
+	builder = transitFilterWhere(builder, filter)
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	//execute
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []*models.TrendPoint
+	for rows.Next() {
+		point := &models.TrendPoint{}
+		err = rows.Scan(&point.Bucket, &point.Carrier, &point.Count, &point.AverageTransitHours, &point.MedianTransitHours)
+		if err != nil {
+			return nil, err
+		}
+		point.Bucket = point.Bucket.UTC()
+		points = append(points, point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+//GetPerformanceWindows returns performance per carrier, and per carrier and state to state lane, for shipments with tracking activity in [since, until)
+func (man ShipmentsManager) GetPerformanceWindows(ctx context.Context, since time.Time, until time.Time) ([]*models.PerformanceWindow, error) {
+	defer observeQuery("ShipmentsManager", "GetPerformanceWindows", time.Now())
+
+	if len(man.tenantID) == 0 {
+		return nil, errMissingTenant
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	const (
+		deliveredInWindow = "s.delivered_at >= ? AND s.delivered_at < ?"
+		eventInWindow     = `EXISTS (SELECT 1 FROM tracking_events e
+			WHERE e.tenant_id = s.tenant_id AND e.shipment_id = s.shipment_id
+			AND e.status_date >= ? AND e.status_date < ?`
+	)
+
+	//build sql, carrier wide rows come from the (carrier) grouping set
+	sql, args, err := psql.Select(
+		"s.carrier",
+		"COALESCE(s.address_from_state_norm, '')",
+		"COALESCE(s.address_to_state_norm, '')",
+		"GROUPING(s.address_from_state_norm)",
+		"COUNT(*)").
+		Column(sq.Expr("COUNT(*) FILTER (WHERE "+deliveredInWindow+")", since, until)).
+		Column(sq.Expr("COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY s.time_in_transit) FILTER (WHERE "+deliveredInWindow+"), 0) / 3600000.0", since, until)).
+		Column(sq.Expr("AVG(CASE WHEN "+eventInWindow+" AND (LOWER(e.status) IN ('failure', 'returned') OR e.substatus_action_required)) THEN 1.0 ELSE 0.0 END)", since, until)).
+		From(shipmentsTableName+" s").
+		Where(sq.Eq{"s.tenant_id": man.tenantID}).
+		Where(eventInWindow+")", since, until).
+		GroupBy("GROUPING SETS ((s.carrier), (s.carrier, s.address_from_state_norm, s.address_to_state_norm))").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	//execute
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []*models.PerformanceWindow{}
+	for rows.Next() {
+		window := &models.PerformanceWindow{}
+		var carrierWide int
+		err = rows.Scan(
+			&window.Carrier,
+			&window.OriginState,
+			&window.DestinationState,
+			&carrierWide,
+			&window.ShipmentCount,
+			&window.DeliveredCount,
+			&window.MedianTransitHours,
+			&window.ExceptionRate)
+		if err != nil {
+			return nil, err
+		}
+
+		//lanes with an unknown state are only counted carrier wide
+		if carrierWide == 0 && (len(window.OriginState) == 0 || len(window.DestinationState) == 0) {
+			continue
+		}
+		windows = append(windows, window)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return windows, nil
+}
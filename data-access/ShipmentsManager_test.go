@@ -0,0 +1,84 @@
+package dataAccess_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func address(city string, state string, zip string) integrations.Address {
+	country := "US"
+	return integrations.Address{City: &city, State: &state, Zip: &zip, Country: &country}
+}
+
+func TestInsertShipmentRefreshesLane(t *testing.T) {
+	fake := &fakeDriver{up: 1, returned: []driver.Value{"shipment-1"}}
+	conn := newFakeConnection(t, fake)
+
+	from := address("New York", "NY", "10001")
+	to := address("San Francisco", "CA", "94105")
+	shipmentID, err := conn.ShipmentManager("tenant-1").InsertShipment(context.Background(), &integrations.WondermentShipment{
+		Carrier:        "ups",
+		TrackingNumber: "1Z999AA10123456784",
+		AddressFrom:    &from,
+		AddressTo:      &to,
+	})
+	if err != nil || shipmentID != "shipment-1" {
+		t.Fatalf("unexpected result %q %v", shipmentID, err)
+	}
+
+	//shipments saved before the lane columns existed get them on re-ingest
+	query := fake.statements()[0].query
+	for _, column := range []string{"address_from_zip3", "address_to_state_norm", "distance_miles", "zone"} {
+		if !strings.Contains(query, column+"=EXCLUDED."+column) {
+			t.Errorf("%s is not refreshed on conflict: %s", column, query)
+		}
+	}
+}
+
+func TestUpdateNormalizedAddresses(t *testing.T) {
+	fake := &fakeDriver{up: 1}
+	conn := newFakeConnection(t, fake)
+
+	err := conn.ShipmentManager("tenant-1").UpdateNormalizedAddresses(context.Background(), &dataAccess.ShipmentAddresses{
+		ShipmentID: "shipment-1",
+		From:       address("new york", "new york", "10001-1234"),
+		To:         address("San Francisco", "CA", "94105"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statement := fake.statements()[0]
+	values := map[string]driver.Value{}
+	for i, column := range []string{"address_from_city_norm", "address_from_state_norm", "address_from_zip_norm", "address_from_zip3"} {
+		values[column] = statement.args[i].Value
+	}
+	if values["address_from_state_norm"] != "NY" || values["address_from_zip_norm"] != "10001" || values["address_from_zip3"] != "100" {
+		t.Errorf("unexpected normalized origin %v in %s", values, statement.query)
+	}
+	if !strings.Contains(statement.query, "zone = $") || statement.args[11].Value == nil {
+		t.Errorf("lane not backfilled: %s %v", statement.query, statement.args)
+	}
+}
+
+func TestGetTransitTimesMostRecent(t *testing.T) {
+	fake := &fakeDriver{up: 1, returned: []driver.Value{int64(86400000), int64(172800000)}}
+	conn := newFakeConnection(t, fake)
+
+	times, err := conn.ShipmentManager("tenant-1").GetTransitTimes(context.Background(), models.TransitFilter{Carrier: "ups"}, 2)
+	if err != nil || len(times) != 2 {
+		t.Fatalf("unexpected result %v %v", times, err)
+	}
+
+	//the limited sample is the latest deliveries, not whichever rows the plan reads first
+	query := fake.statements()[0].query
+	if !strings.Contains(query, "ORDER BY delivered_at DESC NULLS LAST LIMIT") {
+		t.Errorf("sample is not ordered by delivery: %s", query)
+	}
+}
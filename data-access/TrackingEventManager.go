@@ -1,81 +1,281 @@
 package dataAccess
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
 )
 
 const (
 	trackingEventTableName = "tracking_events"
+
+	//maxEventsPerInsert keeps a statement's parameters well under Postgres' limit of 65535
+	maxEventsPerInsert = 1000
 )
 
 type TrackingEventManager struct {
 	dbHelper *sql.DB
+	logger   *logging.Logger
+	tenantID string
+}
+
+//EventInsertCounts reports how many events a bulk insert saved and how many had been saved before
+type EventInsertCounts struct {
+	Inserted   int
+	Duplicates int
 }
 
-func (man TrackingEventManager) InsertTrackingEvent(event integrations.TrackingEvent, shipmentID string) error {
+//InsertTrackingEvents saves the shipment's events with one multi-row statement per maxEventsPerInsert events, skipping those saved before.
+//Statements are not wrapped in a transaction, a failed insert is safe to retry since saved events are skipped.
+func (man TrackingEventManager) InsertTrackingEvents(ctx context.Context, events []*integrations.TrackingEvent, shipmentID string) (EventInsertCounts, error) {
+	defer observeQuery("TrackingEventManager", "InsertTrackingEvents", time.Now())
+
+	counts := EventInsertCounts{}
 	if len(shipmentID) == 0 {
-		return errors.New("invalid shipment ID")
+		return counts, errors.New("invalid shipment ID")
+	}
+	if len(man.tenantID) == 0 {
+		return counts, errMissingTenant
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	for start := 0; start < len(events); start += maxEventsPerInsert {
+		end := start + maxEventsPerInsert
+		if end > len(events) {
+			end = len(events)
+		}
+
+		//build sql, raw location is kept as sent with a normalized copy alongside
+		insert := psql.Insert(trackingEventTableName).Columns(trackingEventColumns...)
+		for _, event := range events[start:end] {
+			insert = insert.Values(man.trackingEventValues(event, shipmentID)...)
+		}
+		sql, args, err := insert.Suffix("ON CONFLICT (tenant_id, event_id) DO NOTHING").ToSql()
+		if err != nil {
+			return counts, err
+		}
+
+		man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+		//execute, only new events count as affected rows
+		res, err := man.dbHelper.ExecContext(ctx, sql, args...)
+		if err != nil {
+			man.logger.Error("query failed", logging.KeyError, err)
+			return counts, err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return counts, err
+		}
+		counts.Inserted += int(affected)
 	}
 
+	counts.Duplicates = len(events) - counts.Inserted
+	return counts, nil
+}
+
+var trackingEventColumns = append([]string{
+	"tenant_id",
+	"event_id",
+	"status_date",
+	"status_details",
+	"location_city",
+	"location_state",
+	"location_zip",
+	"location_country",
+	"substatus_code",
+	"substatus_text",
+	"substatus_action_required",
+	"status",
+	"shipment_id"}, normalizedAddressColumns("location")...)
+
+//trackingEventValues returns the event's row in the order of trackingEventColumns
+func (man TrackingEventManager) trackingEventValues(event *integrations.TrackingEvent, shipmentID string) []interface{} {
 	//avoid seg faults
-	if event.Location == nil {
-		event.Location = &integrations.Address{}
+	location := event.Location
+	if location == nil {
+		location = &integrations.Address{}
 	}
-	if event.SubStatus == nil {
-		event.SubStatus = &integrations.SubStatus{}
+	subStatus := event.SubStatus
+	if subStatus == nil {
+		subStatus = &integrations.SubStatus{}
+	}
+
+	return append([]interface{}{
+		man.tenantID,
+		event.EventID,
+		event.StatusDate,
+		event.StatusDetails,
+		location.City,
+		location.State,
+		location.Zip,
+		location.Country,
+		subStatus.Code,
+		subStatus.Text,
+		subStatus.ActionRequired,
+		event.Status,
+		shipmentID,
+	}, normalizedAddressValues(integrations.NormalizeAddress(location))...)
+}
+
+//EventLocation is a tracking event's location as sent upstream, read back to recompute the normalized columns
+type EventLocation struct {
+	EventID  string
+	Location integrations.Address
+}
+
+//ListEventLocations returns up to limit of the tenant's tracking events ordered by event ID, starting after the given event ID unless it is empty
+func (man TrackingEventManager) ListEventLocations(ctx context.Context, afterEventID string, limit int) ([]*EventLocation, error) {
+	defer observeQuery("TrackingEventManager", "ListEventLocations", time.Now())
+
+	if len(man.tenantID) == 0 {
+		return nil, errMissingTenant
 	}
 
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	//build sql
-	sql, args, err := psql.Insert(trackingEventTableName).
-		Columns(
-			"event_id",
-			"status_date",
-			"status_details",
-			"location_city",
-			"location_state",
-			"location_zip",
-			"location_country",
-			"substatus_code",
-			"substatus_text",
-			"substatus_action_required",
-			"status",
-			"shipment_id").
-		Values(
-			event.EventID,
-			event.StatusDate,
-			event.StatusDetails,
-			event.Location.City,
-			event.Location.State,
-			event.Location.Zip,
-			event.Location.Country,
-			event.SubStatus.Code,
-			event.SubStatus.Text,
-			event.SubStatus.ActionRequired,
-			event.Status,
-			shipmentID,
-		).
-		Suffix("ON CONFLICT (event_id) DO NOTHING").
-		ToSql()
+	builder := psql.Select(
+		"event_id",
+		"location_city",
+		"location_state",
+		"location_zip",
+		"location_country").
+		From(trackingEventTableName).
+		Where(sq.Eq{"tenant_id": man.tenantID})
+	if len(afterEventID) > 0 {
+		builder = builder.Where(sq.Gt{"event_id": afterEventID})
+	}
+	sql, args, err := builder.OrderBy("event_id").Limit(uint64(limit)).ToSql()
 	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
+	if err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*EventLocation
+	for rows.Next() {
+		event := &EventLocation{}
+		err = rows.Scan(&event.EventID, &event.Location.City, &event.Location.State, &event.Location.Zip, &event.Location.Country)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+//UpdateNormalizedLocation recomputes the event's normalized location columns from its location as sent
+func (man TrackingEventManager) UpdateNormalizedLocation(ctx context.Context, event *EventLocation) error {
+	defer observeQuery("TrackingEventManager", "UpdateNormalizedLocation", time.Now())
+
+	if event == nil || len(event.EventID) == 0 {
+		return errors.New("invalid event ID")
+	}
+	if len(man.tenantID) == 0 {
+		return errMissingTenant
+	}
+
+	columns := normalizedAddressColumns("location")
+	values := normalizedAddressValues(integrations.NormalizeAddress(&event.Location))
+	update := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Update(trackingEventTableName)
+	for i, column := range columns {
+		update = update.Set(column, values[i])
+	}
+
+	//build sql
+	sql, args, err := update.Where(sq.Eq{"event_id": event.EventID, "tenant_id": man.tenantID}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
+
+	if _, err := man.dbHelper.ExecContext(ctx, sql, args...); err != nil {
+		man.logger.Error("query failed", logging.KeyError, err)
 		return err
 	}
 
-	fmt.Println(sql, args)
+	return nil
+}
+
+//GetScanEvents returns the located tracking events of the tenant's shipments scanned since the given time, optionally filtered by carrier, ordered by shipment and scan time
+func (man TrackingEventManager) GetScanEvents(ctx context.Context, carrier string, since time.Time) ([]*models.ScanEvent, error) {
+	defer observeQuery("TrackingEventManager", "GetScanEvents", time.Now())
+
+	if len(man.tenantID) == 0 {
+		return nil, errMissingTenant
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	//build sql query
+	builder := psql.Select(
+		"e.shipment_id",
+		"s.carrier",
+		"e.status_date",
+		"e.location_city_norm",
+		"COALESCE(e.location_state_norm, '')",
+		"e.status").
+		From(trackingEventTableName+" e").
+		Join(shipmentsTableName+" s ON s.shipment_id = e.shipment_id AND s.tenant_id = e.tenant_id").
+		Where(sq.Eq{"e.tenant_id": man.tenantID}).
+		Where(sq.NotEq{"e.location_city_norm": nil}).
+		Where(sq.GtOrEq{"e.status_date": since}).
+		OrderBy("e.shipment_id", "e.status_date")
+
+	if len(carrier) != 0 {
+		builder = builder.Where(sq.Eq{"s.carrier": carrier})
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	man.logger.Debug("query", "sql", sql, "args", logging.SQLArgs(args))
 
 	//execute
-	rows, err := man.dbHelper.Query(sql, args...)
+	rows, err := man.dbHelper.QueryContext(ctx, sql, args...)
 	if err != nil {
-		fmt.Println(err)
-		return err
+		man.logger.Error("query failed", logging.KeyError, err)
+		return nil, err
 	}
 	defer rows.Close()
 
-	return nil
+	var events []*models.ScanEvent
+	for rows.Next() {
+		event := &models.ScanEvent{}
+		err = rows.Scan(&event.ShipmentID, &event.Carrier, &event.StatusDate, &event.City, &event.State, &event.Status)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
 }
@@ -0,0 +1,36 @@
+package dataAccess_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+)
+
+func TestInsertTrackingEvents(t *testing.T) {
+	//the first row of every statement was saved before
+	events := &fakeDriver{up: 1, duplicates: 1}
+	conn := newFakeConnection(t, events)
+
+	var trackingEvents []*integrations.TrackingEvent
+	for i := 0; i < 1500; i++ {
+		trackingEvents = append(trackingEvents, &integrations.TrackingEvent{EventID: strconv.Itoa(i), Status: "TRANSIT"})
+	}
+
+	counts, err := conn.TrackingEventManager("tenant-1").InsertTrackingEvents(context.Background(), trackingEvents, "shipment-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	statements := events.statements()
+	if len(statements) != 2 || statements[0].rows != 1000 || statements[1].rows != 500 {
+		t.Errorf("expected statements of 1000 and 500 rows, got %d statements", len(statements))
+	}
+	if counts.Inserted != 1498 || counts.Duplicates != 2 {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+
+	if _, err := conn.TrackingEventManager("").InsertTrackingEvents(context.Background(), trackingEvents, "shipment-1"); err == nil {
+		t.Error("expected a missing tenant to be rejected")
+	}
+}
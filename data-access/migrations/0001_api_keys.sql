@@ -0,0 +1,14 @@
+-- API keys are stored as SHA-256 hashes, the plaintext key is only shown once when created
+CREATE TABLE IF NOT EXISTS api_keys (
+    key_id       UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id    TEXT NOT NULL,
+    name         TEXT NOT NULL DEFAULT '',
+    key_prefix   TEXT NOT NULL,
+    key_hash     TEXT NOT NULL UNIQUE,
+    scopes       TEXT[] NOT NULL DEFAULT '{}',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_client_id_idx ON api_keys (client_id);
@@ -0,0 +1,16 @@
+-- Shipments and tracking events belong to a tenant (merchant), so two tenants tracking the same parcel no longer collide.
+-- Rows ingested before tenancy are assigned to the 'default' tenant.
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS tenant_id TEXT;
+UPDATE api_keys SET tenant_id = client_id WHERE tenant_id IS NULL;
+ALTER TABLE api_keys ALTER COLUMN tenant_id SET NOT NULL;
+
+ALTER TABLE shipments ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE shipments DROP CONSTRAINT IF EXISTS shipments_carrier_tracking_number_key;
+ALTER TABLE shipments ADD CONSTRAINT shipments_tenant_carrier_tracking_number_key UNIQUE (tenant_id, carrier, tracking_number);
+CREATE INDEX IF NOT EXISTS shipments_tenant_carrier_idx ON shipments (tenant_id, carrier);
+
+ALTER TABLE tracking_events ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE tracking_events DROP CONSTRAINT IF EXISTS tracking_events_event_id_key;
+ALTER TABLE tracking_events DROP CONSTRAINT IF EXISTS tracking_events_pkey;
+ALTER TABLE tracking_events ADD CONSTRAINT tracking_events_pkey PRIMARY KEY (tenant_id, event_id);
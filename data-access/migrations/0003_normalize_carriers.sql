@@ -0,0 +1,25 @@
+-- Carriers are stored as canonical registry tokens (see integrations/CarrierRegistry.go).
+-- Rows whose canonical key already exists are left for manual merging rather than violating the unique constraint.
+
+CREATE TEMPORARY TABLE carrier_aliases (alias TEXT PRIMARY KEY, token TEXT NOT NULL);
+INSERT INTO carrier_aliases (alias, token) VALUES
+    ('ups', 'ups'), ('unitedparcelservice', 'ups'), ('upsmailinnovations', 'ups'),
+    ('usps', 'usps'), ('unitedstatespostalservice', 'usps'), ('uspostalservice', 'usps'), ('postalservice', 'usps'),
+    ('fedex', 'fedex'), ('federalexpress', 'fedex'), ('fedexexpress', 'fedex'), ('fedexground', 'fedex'),
+    ('dhlexpress', 'dhl_express'), ('dhl', 'dhl_express'),
+    ('ontrac', 'ontrac'),
+    ('lasership', 'lasership');
+
+UPDATE shipments s
+SET carrier = a.token
+FROM carrier_aliases a
+WHERE a.alias = LOWER(REGEXP_REPLACE(s.carrier, '[^a-zA-Z0-9]', '', 'g'))
+  AND s.carrier <> a.token
+  AND NOT EXISTS (
+      SELECT 1 FROM shipments existing
+      WHERE existing.tenant_id = s.tenant_id
+        AND existing.tracking_number = s.tracking_number
+        AND existing.carrier = a.token
+  );
+
+DROP TABLE carrier_aliases;
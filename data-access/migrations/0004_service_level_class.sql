@@ -0,0 +1,6 @@
+-- Normalized service level speed class and the carrier's promised transit time (business days), see integrations/ServiceLevelCatalog.go
+ALTER TABLE shipments ADD COLUMN IF NOT EXISTS speed_class TEXT;
+ALTER TABLE shipments ADD COLUMN IF NOT EXISTS expected_transit_days_min INTEGER;
+ALTER TABLE shipments ADD COLUMN IF NOT EXISTS expected_transit_days_max INTEGER;
+
+CREATE INDEX IF NOT EXISTS shipments_tenant_speed_class_idx ON shipments (tenant_id, speed_class, carrier);
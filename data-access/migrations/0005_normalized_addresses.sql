@@ -0,0 +1,22 @@
+-- Normalized addresses for lane analytics, see integrations/AddressNormalizer.go. The original address columns keep the raw upstream values.
+-- Normalization lives in Go, so existing rows are backfilled by running normalize-addresses for each tenant after this migration.
+ALTER TABLE shipments
+    ADD COLUMN IF NOT EXISTS address_from_city_norm TEXT,
+    ADD COLUMN IF NOT EXISTS address_from_state_norm TEXT,
+    ADD COLUMN IF NOT EXISTS address_from_zip_norm TEXT,
+    ADD COLUMN IF NOT EXISTS address_from_zip3 TEXT,
+    ADD COLUMN IF NOT EXISTS address_from_country_norm TEXT,
+    ADD COLUMN IF NOT EXISTS address_to_city_norm TEXT,
+    ADD COLUMN IF NOT EXISTS address_to_state_norm TEXT,
+    ADD COLUMN IF NOT EXISTS address_to_zip_norm TEXT,
+    ADD COLUMN IF NOT EXISTS address_to_zip3 TEXT,
+    ADD COLUMN IF NOT EXISTS address_to_country_norm TEXT;
+
+CREATE INDEX IF NOT EXISTS shipments_tenant_lane_idx ON shipments (tenant_id, address_from_zip3, address_to_zip3);
+
+ALTER TABLE tracking_events
+    ADD COLUMN IF NOT EXISTS location_city_norm TEXT,
+    ADD COLUMN IF NOT EXISTS location_state_norm TEXT,
+    ADD COLUMN IF NOT EXISTS location_zip_norm TEXT,
+    ADD COLUMN IF NOT EXISTS location_zip3 TEXT,
+    ADD COLUMN IF NOT EXISTS location_country_norm TEXT;
@@ -0,0 +1,7 @@
+-- Origin to destination distance between ZIP3 centroids and carrier zone, see geo/Lanes.go
+-- Lanes are computed in Go, so existing rows are backfilled by running normalize-addresses for each tenant after this migration.
+ALTER TABLE shipments
+    ADD COLUMN IF NOT EXISTS distance_miles REAL,
+    ADD COLUMN IF NOT EXISTS zone SMALLINT;
+
+CREATE INDEX IF NOT EXISTS shipments_tenant_zone_idx ON shipments (tenant_id, zone);
@@ -0,0 +1,13 @@
+-- When the shipment was delivered, recorded alongside time_in_transit for on-time rates and time series
+ALTER TABLE shipments ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMPTZ;
+
+UPDATE shipments s
+SET delivered_at = e.status_date
+FROM tracking_events e
+WHERE e.tenant_id = s.tenant_id
+  AND e.shipment_id = s.shipment_id
+  AND LOWER(e.status) = 'delivered'
+  AND s.delivered_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS shipments_tenant_delivered_at_idx ON shipments (tenant_id, delivered_at);
+CREATE INDEX IF NOT EXISTS tracking_events_tenant_shipment_idx ON tracking_events (tenant_id, shipment_id);
@@ -0,0 +1,20 @@
+-- History of carrier performance alerts raised by the scheduled evaluator
+CREATE TABLE IF NOT EXISTS alerts (
+    alert_id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id         TEXT NOT NULL,
+    carrier           TEXT NOT NULL,
+    origin_state      TEXT NOT NULL DEFAULT '', -- empty for carrier wide alerts
+    destination_state TEXT NOT NULL DEFAULT '',
+    metric            TEXT NOT NULL,
+    baseline_value    DOUBLE PRECISION NOT NULL,
+    recent_value      DOUBLE PRECISION NOT NULL,
+    threshold_value   DOUBLE PRECISION NOT NULL,
+    baseline_samples  INTEGER NOT NULL,
+    recent_samples    INTEGER NOT NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at      TIMESTAMPTZ,
+    delivery_error    TEXT
+);
+
+CREATE INDEX IF NOT EXISTS alerts_tenant_created_idx ON alerts (tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS alerts_dedup_idx ON alerts (tenant_id, carrier, origin_state, destination_state, metric, created_at DESC);
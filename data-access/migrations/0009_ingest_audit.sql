@@ -0,0 +1,31 @@
+-- Append-only record of every ingest attempt, see data-access/IngestAuditManager.go
+CREATE TABLE IF NOT EXISTS ingest_audit (
+    audit_id        UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id       TEXT NOT NULL,
+    shipment_id     UUID,            -- NULL when the attempt failed before the shipment was saved
+    client_id       TEXT NOT NULL,   -- the API client, or 'backfill' for the backfill command
+    key_id          TEXT,
+    request_id      TEXT,
+    carrier         TEXT NOT NULL,
+    tracking_code   TEXT NOT NULL,
+    outcome         TEXT NOT NULL,   -- success, validation_error, upstream_error or database_error
+    upstream_status INTEGER,         -- NULL when Wonderment was not called or did not respond
+    new_events      INTEGER NOT NULL DEFAULT 0,
+    changed_fields  TEXT[] NOT NULL DEFAULT '{}',
+    error           TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS ingest_audit_tenant_shipment_idx ON ingest_audit (tenant_id, shipment_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS ingest_audit_tenant_tracking_code_idx ON ingest_audit (tenant_id, tracking_code, created_at DESC);
+
+-- Reject updates and deletes so the trail cannot be rewritten
+CREATE OR REPLACE FUNCTION ingest_audit_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ingest_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ingest_audit_append_only ON ingest_audit;
+CREATE TRIGGER ingest_audit_append_only BEFORE UPDATE OR DELETE ON ingest_audit
+    FOR EACH ROW EXECUTE PROCEDURE ingest_audit_append_only();
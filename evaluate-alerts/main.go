@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/elorusso/wonderment-tech-eval/alerting"
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/metrics"
+)
+
+//evaluateAlerts runs on a schedule (e.g. an hourly EventBridge rule), the triggering event is ignored
+func evaluateAlerts(ctx context.Context) (*alerting.Summary, error) {
+	startTime := time.Now()
+	log := logging.FromContext(ctx)
+	defer metrics.FlushEMF()
+
+	config, err := alerting.ConfigFromEnv(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	//warm invocations reuse the pool's connections
+	conn, err := dataAccess.SharedConnection(ctx)
+	if err != nil {
+		log.Error("database connection failed", logging.KeyError, err)
+		return nil, err
+	}
+
+	summary, err := alerting.NewEvaluator(conn, config, log).Run(ctx, time.Now())
+	if err != nil {
+		log.Error("evaluation failed", logging.KeyError, err)
+		return summary, err
+	}
+
+	executionTime := time.Now().Sub(startTime)
+	log.Info("evaluation completed",
+		"execution_time_ms", executionTime,
+		"tenants", summary.Tenants,
+		"raised", summary.Raised,
+		"suppressed", summary.Suppressed,
+		"delivery_failures", summary.DeliveryFailures)
+
+	return summary, nil
+}
+
+func main() {
+	lambda.Start(evaluateAlerts)
+}
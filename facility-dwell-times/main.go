@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/handlers"
+)
+
+func main() {
+	lambda.Start(handlers.API(auth.ScopeAnalytics, handlers.FacilityDwellTimes))
+}
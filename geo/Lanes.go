@@ -0,0 +1,131 @@
+package geo
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	earthRadiusMiles = 3958.8
+)
+
+//Location is the approximate centroid of a ZIP3 prefix
+type Location struct {
+	State     string
+	Latitude  float64
+	Longitude float64
+}
+
+//DistanceBand is a labeled range of origin to destination distances, MaxMiles is exclusive and zero for the last band
+type DistanceBand struct {
+	Label    string
+	MinMiles float64
+	MaxMiles float64
+}
+
+//DistanceBands are the buckets used to group lanes by distance
+var DistanceBands = []DistanceBand{
+	{Label: "0-100", MinMiles: 0, MaxMiles: 100},
+	{Label: "100-250", MinMiles: 100, MaxMiles: 250},
+	{Label: "250-500", MinMiles: 250, MaxMiles: 500},
+	{Label: "500-1000", MinMiles: 500, MaxMiles: 1000},
+	{Label: "1000-2000", MinMiles: 1000, MaxMiles: 2000},
+	{Label: "2000+", MinMiles: 2000},
+}
+
+//zoneLimits are the upper distance limits of zones 1 through 8, zone 8 being unbounded. UPS, FedEx and USPS all zone the contiguous US by distance between ZIP3 centroids along these lines.
+var zoneLimits = []float64{50, 150, 300, 600, 1000, 1400, 1800}
+
+//noncontiguousZone is used for any lane touching Alaska, Hawaii, territories or military addresses
+const noncontiguousZone = 9
+
+var noncontiguousStates = map[string]bool{
+	"AK": true, "HI": true, "PR": true, "VI": true, "GU": true, "AA": true, "AE": true, "AP": true,
+}
+
+//LookupZip3 returns the centroid for a three digit ZIP prefix
+func LookupZip3(zip3 string) (Location, bool) {
+	if len(zip3) != 3 {
+		return Location{}, false
+	}
+	prefix, err := strconv.Atoi(zip3)
+	if err != nil {
+		return Location{}, false
+	}
+
+	index := sort.Search(len(zip3Ranges), func(i int) bool {
+		return zip3Ranges[i].last >= prefix
+	})
+	if index == len(zip3Ranges) || zip3Ranges[index].first > prefix {
+		return Location{}, false
+	}
+
+	zipRange := zip3Ranges[index]
+	return Location{
+		State:     zipRange.state,
+		Latitude:  zipRange.latitude,
+		Longitude: zipRange.longitude,
+	}, true
+}
+
+//DistanceMiles returns the great circle distance between two locations
+func DistanceMiles(from Location, to Location) float64 {
+	lat1 := from.Latitude * math.Pi / 180
+	lat2 := to.Latitude * math.Pi / 180
+	deltaLat := (to.Latitude - from.Latitude) * math.Pi / 180
+	deltaLong := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLong/2)*math.Sin(deltaLong/2)
+
+	return 2 * earthRadiusMiles * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+//Zone returns the carrier zone, 1 through 8 by distance within the contiguous US and 9 for lanes touching noncontiguous areas
+func Zone(from Location, to Location) int {
+	if noncontiguousStates[from.State] || noncontiguousStates[to.State] {
+		return noncontiguousZone
+	}
+
+	distance := DistanceMiles(from, to)
+	for i, limit := range zoneLimits {
+		if distance <= limit {
+			return i + 1
+		}
+	}
+	return len(zoneLimits) + 1
+}
+
+//BandForDistance returns the distance band containing the distance
+func BandForDistance(miles float64) DistanceBand {
+	for _, band := range DistanceBands {
+		if band.MaxMiles == 0 || miles < band.MaxMiles {
+			return band
+		}
+	}
+	return DistanceBands[len(DistanceBands)-1]
+}
+
+//Lane is the distance and zone between an origin and destination
+type Lane struct {
+	DistanceMiles float64
+	Zone          int
+}
+
+//LaneBetweenZip3s computes the lane between two ZIP3 prefixes. The second return value is false when either prefix is unknown.
+func LaneBetweenZip3s(fromZip3 string, toZip3 string) (Lane, bool) {
+	from, ok := LookupZip3(fromZip3)
+	if !ok {
+		return Lane{}, false
+	}
+	to, ok := LookupZip3(toZip3)
+	if !ok {
+		return Lane{}, false
+	}
+
+	return Lane{
+		DistanceMiles: DistanceMiles(from, to),
+		Zone:          Zone(from, to),
+	}, true
+}
@@ -0,0 +1,61 @@
+package geo_test
+
+import (
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/geo"
+)
+
+func TestLookupZip3(t *testing.T) {
+	cases := map[string]string{
+		"005": "NY",
+		"021": "MA",
+		"100": "NY",
+		"733": "TX",
+		"941": "CA",
+		"999": "AK",
+	}
+	for zip3, state := range cases {
+		location, ok := geo.LookupZip3(zip3)
+		if !ok || location.State != state {
+			t.Errorf("%s: expected %s, got %+v", zip3, state, location)
+		}
+	}
+
+	for _, zip3 := range []string{"000", "269", "12", "abc"} {
+		if _, ok := geo.LookupZip3(zip3); ok {
+			t.Errorf("%s: expected no location", zip3)
+		}
+	}
+}
+
+func TestLaneBetweenZip3s(t *testing.T) {
+	//New York City to Los Angeles is roughly 2,450 miles
+	lane, ok := geo.LaneBetweenZip3s("100", "900")
+	if !ok {
+		t.Fatal("expected lane")
+	}
+	if lane.DistanceMiles < 2350 || lane.DistanceMiles > 2550 {
+		t.Errorf("unexpected distance %f", lane.DistanceMiles)
+	}
+	if lane.Zone != 8 {
+		t.Errorf("expected zone 8, got %d", lane.Zone)
+	}
+
+	lane, ok = geo.LaneBetweenZip3s("100", "101")
+	if !ok || lane.Zone != 1 || lane.DistanceMiles != 0 {
+		t.Errorf("expected local lane, got %+v", lane)
+	}
+
+	lane, ok = geo.LaneBetweenZip3s("941", "967")
+	if !ok || lane.Zone != 9 {
+		t.Errorf("expected noncontiguous zone, got %+v", lane)
+	}
+
+	if band := geo.BandForDistance(2450); band.Label != "2000+" {
+		t.Errorf("unexpected band %s", band.Label)
+	}
+	if band := geo.BandForDistance(99.9); band.Label != "0-100" {
+		t.Errorf("unexpected band %s", band.Label)
+	}
+}
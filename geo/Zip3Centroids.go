@@ -0,0 +1,117 @@
+package geo
+
+//zip3Range maps an inclusive range of US ZIP3 prefixes to the approximate centroid of the area they serve.
+//Large states are split into regions so cross-state distances are realistic; prefixes within a range share a centroid.
+type zip3Range struct {
+	first     int
+	last      int
+	state     string
+	latitude  float64
+	longitude float64
+}
+
+//zip3Ranges is sorted by first prefix. Source: USPS ZIP3 to state assignments, centroids rounded to a tenth of a degree.
+var zip3Ranges = []zip3Range{
+	{5, 5, "NY", 40.8, -73.0},
+	{6, 7, "PR", 18.3, -66.4},
+	{8, 8, "VI", 18.3, -64.9},
+	{9, 9, "PR", 18.4, -66.1},
+	{10, 13, "MA", 42.2, -72.6},
+	{14, 17, "MA", 42.4, -71.6},
+	{18, 27, "MA", 42.3, -71.1},
+	{28, 29, "RI", 41.8, -71.4},
+	{30, 38, "NH", 43.2, -71.5},
+	{39, 49, "ME", 44.7, -69.4},
+	{50, 54, "VT", 44.1, -72.7},
+	{55, 55, "MA", 42.7, -71.2},
+	{56, 59, "VT", 44.5, -73.0},
+	{60, 69, "CT", 41.6, -72.7},
+	{70, 79, "NJ", 40.7, -74.3},
+	{80, 89, "NJ", 39.8, -74.8},
+	{90, 99, "AE", 50.1, 8.7},
+	{100, 104, "NY", 40.8, -73.9},
+	{105, 109, "NY", 41.1, -73.8},
+	{110, 119, "NY", 40.8, -73.3},
+	{120, 129, "NY", 42.7, -73.8},
+	{130, 139, "NY", 43.0, -76.1},
+	{140, 149, "NY", 42.9, -78.0},
+	{150, 168, "PA", 40.6, -79.6},
+	{169, 179, "PA", 40.5, -77.0},
+	{180, 196, "PA", 40.2, -75.5},
+	{197, 199, "DE", 39.3, -75.5},
+	{200, 200, "DC", 38.9, -77.0},
+	{201, 201, "VA", 38.9, -77.4},
+	{202, 205, "DC", 38.9, -77.0},
+	{206, 219, "MD", 39.1, -76.8},
+	{220, 223, "VA", 38.8, -77.2},
+	{224, 239, "VA", 37.5, -77.3},
+	{240, 246, "VA", 37.1, -80.0},
+	{247, 268, "WV", 38.6, -80.6},
+	{270, 279, "NC", 35.8, -79.5},
+	{280, 289, "NC", 35.4, -81.3},
+	{290, 299, "SC", 34.0, -81.0},
+	{300, 312, "GA", 33.7, -84.2},
+	{313, 319, "GA", 31.8, -82.4},
+	{320, 329, "FL", 29.4, -82.0},
+	{330, 334, "FL", 26.3, -80.3},
+	{335, 339, "FL", 27.8, -82.4},
+	{340, 340, "AA", 25.8, -80.3},
+	{341, 349, "FL", 27.0, -81.5},
+	{350, 369, "AL", 32.8, -86.8},
+	{370, 374, "TN", 36.0, -86.6},
+	{375, 385, "TN", 35.6, -88.5},
+	{386, 397, "MS", 32.6, -89.7},
+	{398, 399, "GA", 31.5, -84.2},
+	{400, 427, "KY", 37.8, -85.3},
+	{430, 438, "OH", 40.0, -82.6},
+	{439, 449, "OH", 41.2, -81.5},
+	{450, 459, "OH", 39.5, -84.2},
+	{460, 479, "IN", 39.8, -86.3},
+	{480, 492, "MI", 42.5, -83.6},
+	{493, 499, "MI", 43.6, -85.4},
+	{500, 528, "IA", 41.9, -93.4},
+	{530, 549, "WI", 43.9, -88.9},
+	{550, 567, "MN", 45.3, -93.9},
+	{569, 569, "DC", 38.9, -77.0},
+	{570, 577, "SD", 44.1, -99.3},
+	{580, 588, "ND", 47.3, -100.5},
+	{590, 599, "MT", 46.8, -110.4},
+	{600, 608, "IL", 41.8, -87.9},
+	{609, 629, "IL", 40.0, -89.2},
+	{630, 641, "MO", 38.6, -91.5},
+	{644, 658, "MO", 37.9, -93.3},
+	{660, 679, "KS", 38.5, -97.4},
+	{680, 693, "NE", 41.2, -98.5},
+	{700, 714, "LA", 30.7, -91.5},
+	{716, 729, "AR", 34.8, -92.3},
+	{730, 732, "OK", 35.5, -97.5},
+	{733, 733, "TX", 30.3, -97.7},
+	{734, 749, "OK", 35.6, -96.3},
+	{750, 759, "TX", 32.8, -96.8},
+	{760, 769, "TX", 32.4, -98.6},
+	{770, 779, "TX", 29.8, -95.4},
+	{780, 789, "TX", 29.8, -98.2},
+	{790, 799, "TX", 32.3, -101.9},
+	{800, 816, "CO", 39.4, -105.3},
+	{820, 831, "WY", 42.9, -107.5},
+	{832, 838, "ID", 44.1, -115.0},
+	{840, 847, "UT", 40.4, -111.8},
+	{850, 865, "AZ", 33.5, -111.9},
+	{870, 884, "NM", 35.0, -106.4},
+	{885, 885, "TX", 31.8, -106.4},
+	{889, 898, "NV", 37.2, -116.3},
+	{900, 918, "CA", 34.1, -118.2},
+	{919, 921, "CA", 32.8, -117.0},
+	{922, 928, "CA", 33.8, -117.3},
+	{930, 935, "CA", 35.0, -119.5},
+	{936, 939, "CA", 36.7, -120.6},
+	{940, 951, "CA", 37.6, -122.1},
+	{952, 961, "CA", 38.9, -121.3},
+	{962, 966, "AP", 35.5, 139.7},
+	{967, 968, "HI", 21.3, -157.8},
+	{969, 969, "GU", 13.4, 144.8},
+	{970, 979, "OR", 44.6, -122.6},
+	{980, 986, "WA", 47.4, -122.3},
+	{988, 994, "WA", 47.2, -119.0},
+	{995, 999, "AK", 61.2, -149.9},
+}
@@ -6,5 +6,4 @@ require (
 	github.com/Masterminds/squirrel v1.5.0
 	github.com/aws/aws-lambda-go v1.23.0
 	github.com/lib/pq v1.9.0
-	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
 )
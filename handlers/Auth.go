@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const (
+	apiKeyHeader = "x-api-key"
+)
+
+//RequireAPIKey rejects requests without a valid API key granting the scope, and attaches the calling client to the context of those it lets through.
+//The context's logger is tagged with the client and tenant.
+func RequireAPIKey(scope string, next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		log := logging.FromContext(ctx)
+
+		key := apiKeyFromPayload(payload)
+		if len(key) == 0 {
+			return errorResponse(ctx, apiKeyRequiredError())
+		}
+
+		databaseConn, err := connectDatabase(ctx)
+		if err != nil {
+			return errorResponse(ctx, err)
+		}
+
+		client, err := auth.Authenticate(ctx, databaseConn.WithLogger(log).APIKeyManager(), key, scope)
+		if err == auth.ErrInvalidKey {
+			return errorResponse(ctx, models.NewAPIError(http.StatusUnauthorized, models.ErrorCodeUnauthorized, err.Error()))
+		} else if err == auth.ErrForbidden {
+			return errorResponse(ctx, models.NewAPIError(http.StatusForbidden, models.ErrorCodeForbidden, err.Error()))
+		} else if err != nil {
+			log.Error("authentication failed", logging.KeyError, err)
+			return errorResponse(ctx, internalError())
+		}
+
+		ctx = logging.WithLogger(ctx, log.With(logging.KeyClientID, client.ClientID, logging.KeyTenantID, client.TenantID))
+		return next(auth.WithClient(ctx, client), payload)
+	}
+}
+
+//apiKeyFromPayload reads the key from the x-api-key header, falling back to a bearer token
+func apiKeyFromPayload(payload *models.APIGatewayPayload) string {
+	if key := payload.Header(apiKeyHeader); len(key) > 0 {
+		return key
+	}
+
+	authorization := payload.Header("authorization")
+	if len(authorization) > 7 && strings.EqualFold(authorization[:7], "bearer ") {
+		return strings.TrimSpace(authorization[7:])
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//averageTimeInTransitResponse is the body AverageTimeInTransit answers with
+type averageTimeInTransitResponse struct {
+	AverageTimeInTransit int                        `json:"average_time_in_transit"`
+	Carrier              string                     `json:"carrier,omitempty"`
+	SpeedClass           string                     `json:"speed_class,omitempty"`
+	GroupBy              string                     `json:"group_by,omitempty"`
+	Groups               []*models.TransitTimeGroup `json:"groups,omitempty"`
+}
+
+//AverageTimeInTransit returns the average time in transit of delivered shipments, optionally filtered by carrier
+func AverageTimeInTransit(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+	log := logging.FromContext(ctx)
+
+	//analytics only cover the caller's tenant
+	client := auth.ClientFromContext(ctx)
+	if client == nil {
+		return errorResponse(ctx, apiKeyRequiredError())
+	}
+
+	params := &struct {
+		Carrier    string `query:"carrier"`
+		SpeedClass string `query:"speed_class"`
+		GroupBy    string `query:"group_by"`
+	}{}
+	if err := Bind(payload, params); err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	//check for carrier parameter
+	carrier := params.Carrier
+	if len(carrier) > 0 {
+		token, err := integrations.NormalizeCarrier(carrier)
+		if err != nil {
+			return errorResponse(ctx, models.NewValidationError("carrier", models.FieldCodeUnknownValue, err.Error()))
+		}
+		carrier = token
+	}
+
+	//check for speed class parameter, so carriers can be compared like for like
+	var speedClass integrations.SpeedClass
+	if len(params.SpeedClass) > 0 {
+		var ok bool
+		speedClass, ok = integrations.ParseSpeedClass(params.SpeedClass)
+		if !ok {
+			return errorResponse(ctx, models.NewValidationError("speed_class", models.FieldCodeUnknownValue, fmt.Sprintf("Unknown speed class %q", params.SpeedClass)))
+		}
+	}
+
+	//check for grouping parameter, to bucket transit times by lane
+	groupBy := dataAccess.TransitGrouping(params.GroupBy)
+	if len(groupBy) > 0 && groupBy != dataAccess.GroupByZone && groupBy != dataAccess.GroupByDistanceBand {
+		return errorResponse(ctx, models.NewValidationError("group_by", models.FieldCodeUnknownValue, fmt.Sprintf("Unknown group_by %q, expected zone or distance_band", groupBy)))
+	}
+
+	//connect to db
+	databaseConn, err := connectDatabase(ctx)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	shipmentManager := databaseConn.WithLogger(log).ShipmentManager(client.TenantID)
+
+	avgTimeInTransit, err := shipmentManager.GetAverageTimeInTransit(ctx, carrier, speedClass)
+	if err != nil {
+		log.Error("query failed", logging.KeyError, err)
+		return errorResponse(ctx, internalError())
+	}
+
+	var groups []*models.TransitTimeGroup
+	if len(groupBy) > 0 {
+		groups, err = shipmentManager.GetAverageTimeInTransitByGroup(ctx, carrier, speedClass, groupBy)
+		if err != nil {
+			log.Error("query failed", logging.KeyError, err)
+			return errorResponse(ctx, internalError())
+		}
+	}
+
+	//create response
+	successResponse := &averageTimeInTransitResponse{
+		AverageTimeInTransit: avgTimeInTransit,
+		Carrier:              carrier,
+		SpeedClass:           string(speedClass),
+		GroupBy:              string(groupBy),
+		Groups:               groups,
+	}
+	return jsonResponse(ctx, http.StatusOK, successResponse)
+}
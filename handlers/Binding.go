@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//Bind fills the struct dst points to from the request. Fields tagged `path` and `query` are read from the path and query string parameters,
+//then a body is decoded over them: forms by the fields' `form` tags and anything else as JSON by their `json` tags.
+//Fields tagged `binding:"required"` must be set by one of them. Failures are returned as *models.APIError naming the offending field.
+func Bind(payload *models.APIGatewayPayload, dst interface{}) error {
+	value := reflect.ValueOf(dst)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Bind expects a pointer to a struct, got %T", dst)
+	}
+	value = value.Elem()
+	fields := value.Type()
+
+	//path and query parameters
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		if name := tagName(field, "path"); len(name) > 0 {
+			if param, ok := payload.PathParameters[name]; ok {
+				if err := setField(value.Field(i), name, param); err != nil {
+					return err
+				}
+			}
+		}
+		if name := tagName(field, "query"); len(name) > 0 {
+			if param := payload.QueryParam(name); len(param) > 0 {
+				if err := setField(value.Field(i), name, param); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	//body
+	body, err := payload.DecodedBody()
+	if err != nil {
+		return models.NewAPIError(http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Request body is not valid base64")
+	}
+	if len(body) > 0 {
+		if strings.HasPrefix(payload.Header("content-type"), "application/x-www-form-urlencoded") {
+			form, err := url.ParseQuery(string(body))
+			if err != nil {
+				return models.NewAPIError(http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Request body is not a valid form")
+			}
+			for i := 0; i < fields.NumField(); i++ {
+				name := tagName(fields.Field(i), "form")
+				if param := form.Get(name); len(name) > 0 && len(param) > 0 {
+					if err := setField(value.Field(i), name, param); err != nil {
+						return err
+					}
+				}
+			}
+		} else if err := json.Unmarshal(body, dst); err != nil {
+			return invalidJSONError(err)
+		}
+	}
+
+	//required fields
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		if field.Tag.Get("binding") == "required" && value.Field(i).IsZero() {
+			name := paramName(field)
+			return models.NewValidationError(name, models.FieldCodeRequired, fmt.Sprintf("%s is required", name))
+		}
+	}
+	return nil
+}
+
+//setField parses a string parameter into the field's type
+func setField(field reflect.Value, name string, param string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(param)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(param, 10, field.Type().Bits())
+		if err != nil {
+			return models.NewValidationError(name, models.FieldCodeInvalidType, fmt.Sprintf("%s must be an integer", name))
+		}
+		field.SetInt(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(param, field.Type().Bits())
+		if err != nil {
+			return models.NewValidationError(name, models.FieldCodeInvalidType, fmt.Sprintf("%s must be a number", name))
+		}
+		field.SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(param)
+		if err != nil {
+			return models.NewValidationError(name, models.FieldCodeInvalidType, fmt.Sprintf("%s must be true or false", name))
+		}
+		field.SetBool(parsed)
+	default:
+		return fmt.Errorf("Cannot bind %s to a %s field", name, field.Kind())
+	}
+	return nil
+}
+
+func tagName(field reflect.StructField, key string) string {
+	name := strings.Split(field.Tag.Get(key), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+//paramName is the name clients use for the field, whichever source it was bound from
+func paramName(field reflect.StructField) string {
+	for _, key := range []string{"json", "query", "path", "form"} {
+		if name := tagName(field, key); len(name) > 0 {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,77 @@
+package handlers_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/handlers"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+type bindParams struct {
+	ShipmentID string  `path:"shipment_id"`
+	Carrier    string  `json:"carrier" query:"carrier" form:"carrier"`
+	Limit      int     `json:"limit" query:"limit"`
+	Confidence float64 `query:"confidence"`
+	Test       bool    `query:"test"`
+	Tracking   string  `json:"tracking_code" form:"tracking_code" binding:"required"`
+}
+
+func TestBind(t *testing.T) {
+	payload := &models.APIGatewayPayload{
+		PathParameters:        map[string]string{"shipment_id": "abc"},
+		QueryStringParameters: map[string]string{"carrier": "ups", "limit": "5", "confidence": "0.9", "test": "true"},
+		Body:                  `{"tracking_code":"1Z","carrier":"fedex"}`,
+	}
+
+	params := &bindParams{}
+	if err := handlers.Bind(payload, params); err != nil {
+		t.Fatal(err)
+	}
+
+	//the body is decoded over query parameters
+	expected := bindParams{ShipmentID: "abc", Carrier: "fedex", Limit: 5, Confidence: 0.9, Test: true, Tracking: "1Z"}
+	if *params != expected {
+		t.Errorf("expected %+v, got %+v", expected, *params)
+	}
+}
+
+func TestBindForm(t *testing.T) {
+	payload := &models.APIGatewayPayload{
+		Headers: map[string]string{"content-type": "application/x-www-form-urlencoded"},
+		Body:    "carrier=usps&tracking_code=9400",
+	}
+
+	params := &bindParams{}
+	if err := handlers.Bind(payload, params); err != nil {
+		t.Fatal(err)
+	}
+	if params.Carrier != "usps" || params.Tracking != "9400" {
+		t.Errorf("form not bound: %+v", params)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		payload   *models.APIGatewayPayload
+		field     string
+		fieldCode string
+	}{
+		{&models.APIGatewayPayload{}, "tracking_code", models.FieldCodeRequired},
+		{&models.APIGatewayPayload{QueryStringParameters: map[string]string{"limit": "ten"}, Body: `{"tracking_code":"1Z"}`}, "limit", models.FieldCodeInvalidType},
+		{&models.APIGatewayPayload{Body: `{"tracking_code":"1Z","limit":"ten"}`}, "limit", models.FieldCodeInvalidType},
+	}
+
+	for _, test := range tests {
+		err := handlers.Bind(test.payload, &bindParams{})
+
+		var apiErr *models.APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("expected an API error for %s, got %v", test.field, err)
+			continue
+		}
+		if len(apiErr.Details) != 1 || apiErr.Details[0].Field != test.field || apiErr.Details[0].Code != test.fieldCode {
+			t.Errorf("unexpected details for %s: %+v", test.field, apiErr.Details)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//CORSConfig controls which browser origins may call the API. With no allowed origins no CORS headers are sent.
+type CORSConfig struct {
+	AllowedOrigins []string //"*" allows any origin
+	AllowedMethods []string
+	AllowedHeaders []string
+	MaxAge         time.Duration //how long browsers may cache a preflight
+}
+
+//DefaultCORSConfig allows the methods and headers the API uses, but no origins
+var DefaultCORSConfig = CORSConfig{
+	AllowedMethods: []string{http.MethodGet, http.MethodPost},
+	AllowedHeaders: []string{"content-type", apiKeyHeader, "authorization", "traceparent"},
+	MaxAge:         10 * time.Minute,
+}
+
+//CORSConfigFromEnv overrides the default config with CORS_ALLOWED_ORIGINS, a comma separated list of origins
+func CORSConfigFromEnv(getenv func(string) string) CORSConfig {
+	config := DefaultCORSConfig
+	for _, origin := range strings.Split(getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); len(origin) > 0 {
+			config.AllowedOrigins = append(config.AllowedOrigins, origin)
+		}
+	}
+	return config
+}
+
+//allowOrigin returns the Access-Control-Allow-Origin value for the request's origin, or empty if it is not allowed
+func (config CORSConfig) allowOrigin(origin string) string {
+	if len(origin) == 0 {
+		return ""
+	}
+	for _, allowed := range config.AllowedOrigins {
+		if allowed == "*" {
+			return "*"
+		} else if strings.EqualFold(allowed, origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+//WithCORS answers preflight requests itself and adds CORS headers to the handler's responses to allowed origins
+func WithCORS(config CORSConfig) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+			allowOrigin := config.allowOrigin(payload.Header("origin"))
+
+			//preflights carry no API key, so they never reach the handler
+			if payload.Method() == http.MethodOptions {
+				response := &models.APIGatewayResponse{
+					StatusCode: http.StatusNoContent,
+					Headers:    map[string]string{},
+				}
+				if len(allowOrigin) > 0 {
+					response.Headers["access-control-allow-origin"] = allowOrigin
+					response.Headers["access-control-allow-methods"] = strings.Join(config.AllowedMethods, ", ")
+					response.Headers["access-control-allow-headers"] = strings.Join(config.AllowedHeaders, ", ")
+					response.Headers["access-control-max-age"] = strconv.Itoa(int(config.MaxAge / time.Second))
+					response.Headers["vary"] = "Origin"
+				}
+				return response, nil
+			}
+
+			response, err := next(ctx, payload)
+			if err != nil || response == nil || len(allowOrigin) == 0 {
+				return response, err
+			}
+
+			if response.Headers == nil {
+				response.Headers = map[string]string{}
+			}
+			response.Headers["access-control-allow-origin"] = allowOrigin
+			response.Headers["vary"] = "Origin"
+			return response, nil
+		}
+	}
+}
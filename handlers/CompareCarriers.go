@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//compareCarriersResponse is the body CompareCarriers answers with
+type compareCarriersResponse struct {
+	OriginZip3       string                 `json:"origin_zip3,omitempty"`
+	OriginState      string                 `json:"origin_state,omitempty"`
+	DestinationZip3  string                 `json:"destination_zip3,omitempty"`
+	DestinationState string                 `json:"destination_state,omitempty"`
+	SpeedClass       string                 `json:"speed_class,omitempty"`
+	RankBy           analytics.RankMetric   `json:"rank_by"`
+	Carriers         []*models.CarrierStats `json:"carriers"`
+}
+
+//CompareCarriers returns side by side statistics for every carrier and service level on a lane, ranked by the selected metric
+func CompareCarriers(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+	log := logging.FromContext(ctx)
+
+	//comparisons only cover the caller's tenant
+	client := auth.ClientFromContext(ctx)
+	if client == nil {
+		return errorResponse(ctx, apiKeyRequiredError())
+	}
+
+	//check lane parameters, a ZIP narrows to its ZIP3 and a state to the whole region
+	filter := models.TransitFilter{
+		OriginZip3:       integrations.Zip3(integrations.NormalizeZip(payload.QueryParam("origin_zip"), "US"), "US"),
+		DestinationZip3:  integrations.Zip3(integrations.NormalizeZip(payload.QueryParam("destination_zip"), "US"), "US"),
+		OriginState:      normalizedStateParam(payload, "origin_state"),
+		DestinationState: normalizedStateParam(payload, "destination_state"),
+	}
+	if len(filter.OriginZip3) == 0 && len(filter.OriginState) == 0 {
+		return errorResponse(ctx, models.NewValidationError("origin_zip", models.FieldCodeRequired, "origin_zip or origin_state is required"))
+	}
+	if len(filter.DestinationZip3) == 0 && len(filter.DestinationState) == 0 {
+		return errorResponse(ctx, models.NewValidationError("destination_zip", models.FieldCodeRequired, "destination_zip or destination_state is required"))
+	}
+
+	//check for speed class parameter
+	if speedClassVal := payload.QueryParam("speed_class"); len(speedClassVal) > 0 {
+		speedClass, ok := integrations.ParseSpeedClass(speedClassVal)
+		if !ok {
+			return errorResponse(ctx, models.NewValidationError("speed_class", models.FieldCodeUnknownValue, fmt.Sprintf("Unknown speed class %q", speedClassVal)))
+		}
+		filter.SpeedClass = string(speedClass)
+	}
+
+	//check ranking parameters
+	rankBy := analytics.RankByMedianTransit
+	if rankByVal := payload.QueryParam("rank_by"); len(rankByVal) > 0 {
+		var err error
+		rankBy, err = analytics.ParseRankMetric(rankByVal)
+		if err != nil {
+			return errorResponse(ctx, models.NewValidationError("rank_by", models.FieldCodeUnknownValue, err.Error()))
+		}
+	}
+	minSamples, err := intQueryParam(payload, "min_samples", 1)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	//connect to db
+	databaseConn, err := connectDatabase(ctx)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	stats, err := databaseConn.WithLogger(log).ShipmentManager(client.TenantID).GetCarrierStats(ctx, filter)
+	if err != nil {
+		log.Error("query failed", logging.KeyError, err)
+		return errorResponse(ctx, internalError())
+	}
+
+	//create response
+	successResponse := &compareCarriersResponse{
+		OriginZip3:       filter.OriginZip3,
+		OriginState:      filter.OriginState,
+		DestinationZip3:  filter.DestinationZip3,
+		DestinationState: filter.DestinationState,
+		SpeedClass:       filter.SpeedClass,
+		RankBy:           rankBy,
+		Carriers:         analytics.RankCarriers(stats, rankBy, minSamples),
+	}
+	return jsonResponse(ctx, http.StatusOK, successResponse)
+}
+
+func normalizedStateParam(payload *models.APIGatewayPayload, name string) string {
+	state := payload.QueryParam(name)
+	if len(state) == 0 {
+		return ""
+	}
+	return integrations.NormalizeState(state, "US")
+}
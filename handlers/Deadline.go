@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//DeadlineConfig bounds how long a handler may run
+type DeadlineConfig struct {
+	SafetyMargin   time.Duration //reserved before the Lambda deadline to write the response and audit entries
+	DefaultTimeout time.Duration //used when the context has no deadline, e.g. on the HTTP server
+}
+
+//DefaultDeadlineConfig matches API Gateway, which gives up on integrations after 29 seconds
+var DefaultDeadlineConfig = DeadlineConfig{
+	SafetyMargin:   500 * time.Millisecond,
+	DefaultTimeout: 29 * time.Second,
+}
+
+//DeadlineConfigFromEnv overrides DefaultDeadlineConfig with REQUEST_DEADLINE_MARGIN and REQUEST_TIMEOUT, as durations (e.g. 500ms)
+func DeadlineConfigFromEnv(getenv func(string) string) (DeadlineConfig, error) {
+	config := DefaultDeadlineConfig
+
+	durations := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"REQUEST_DEADLINE_MARGIN", &config.SafetyMargin},
+		{"REQUEST_TIMEOUT", &config.DefaultTimeout},
+	}
+	for _, d := range durations {
+		if raw := getenv(d.name); len(raw) != 0 {
+			parsed, err := time.ParseDuration(raw)
+			if err != nil || parsed < 0 {
+				return config, fmt.Errorf("Invalid %s %q", d.name, raw)
+			}
+			*d.value = parsed
+		}
+	}
+
+	if config.DefaultTimeout == 0 {
+		return config, fmt.Errorf("REQUEST_TIMEOUT must be positive")
+	}
+	return config, nil
+}
+
+//deadlineConfigFromEnv is DeadlineConfigFromEnv for the middleware chains, which cannot fail, invalid settings are logged and the defaults used
+func deadlineConfigFromEnv() DeadlineConfig {
+	config, err := DeadlineConfigFromEnv(os.Getenv)
+	if err != nil {
+		logging.Default().Warn("invalid deadline config, using defaults", logging.KeyError, err)
+		return DefaultDeadlineConfig
+	}
+	return config
+}
+
+//WithDeadline cancels the handler's context the safety margin before the Lambda deadline, or after the default timeout when there is none,
+//so in-flight queries are cancelled while there is still time to answer. Server errors returned after the deadline become a retryable 504.
+func WithDeadline(config DeadlineConfig) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+			deadline, ok := ctx.Deadline()
+			if ok {
+				deadline = deadline.Add(-config.SafetyMargin)
+			} else {
+				deadline = time.Now().Add(config.DefaultTimeout)
+			}
+
+			ctx, cancel := context.WithDeadline(ctx, deadline)
+			defer cancel()
+
+			return next(ctx, payload)
+		}
+	}
+}
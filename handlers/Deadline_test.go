@@ -0,0 +1,74 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/handlers"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func TestWithDeadline(t *testing.T) {
+	config := handlers.DeadlineConfig{SafetyMargin: time.Second, DefaultTimeout: time.Minute}
+
+	var got time.Time
+	recordDeadline := func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		got, _ = ctx.Deadline()
+		return &models.APIGatewayResponse{StatusCode: http.StatusOK}, nil
+	}
+	handler := handlers.WithDeadline(config)(recordDeadline)
+
+	//the Lambda deadline is brought forward by the margin
+	lambdaDeadline := time.Now().Add(10 * time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), lambdaDeadline)
+	defer cancel()
+	handler(ctx, &models.APIGatewayPayload{})
+	if !got.Equal(lambdaDeadline.Add(-time.Second)) {
+		t.Errorf("expected deadline %v, got %v", lambdaDeadline.Add(-time.Second), got)
+	}
+
+	//without one the default timeout applies
+	handler(context.Background(), &models.APIGatewayPayload{})
+	if remaining := time.Until(got); remaining <= 50*time.Second || remaining > time.Minute {
+		t.Errorf("expected the default timeout, %v remaining", remaining)
+	}
+}
+
+func TestWithDeadlineTimeout(t *testing.T) {
+	//less time is left than the margin, so the handler fails once its context is cancelled, as a query would
+	config := handlers.DeadlineConfig{SafetyMargin: time.Second, DefaultTimeout: time.Minute}
+	stub := func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		<-ctx.Done()
+		panic(ctx.Err())
+	}
+	handler := handlers.WithDeadline(config)(handlers.WithRecovery(stub))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	response, err := handler(ctx, &models.APIGatewayPayload{})
+	if err != nil || response.StatusCode != http.StatusGatewayTimeout {
+		t.Fatalf("expected a 504, got %+v %v", response, err)
+	}
+
+	var body models.APIError
+	json.Unmarshal([]byte(response.Body), &body)
+	if body.Code != models.ErrorCodeTimeout || !body.Retryable {
+		t.Errorf("unexpected body %s", response.Body)
+	}
+}
+
+func TestDeadlineConfigFromEnv(t *testing.T) {
+	env := map[string]string{"REQUEST_DEADLINE_MARGIN": "250ms"}
+	config, err := handlers.DeadlineConfigFromEnv(func(name string) string { return env[name] })
+	if err != nil || config.SafetyMargin != 250*time.Millisecond || config.DefaultTimeout != handlers.DefaultDeadlineConfig.DefaultTimeout {
+		t.Errorf("unexpected config %+v %v", config, err)
+	}
+
+	env["REQUEST_TIMEOUT"] = "soon"
+	if _, err := handlers.DeadlineConfigFromEnv(func(name string) string { return env[name] }); err == nil {
+		t.Error("expected an invalid timeout to be rejected")
+	}
+}
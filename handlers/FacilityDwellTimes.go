@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const (
+	defaultDwellDays       = 30
+	maxDwellDays           = 90 //every located scan in the window is loaded to compute dwell times
+	defaultDwellMinSamples = 5
+	defaultDwellLimit      = 10
+)
+
+//facilityDwellTimesResponse is the body FacilityDwellTimes answers with
+type facilityDwellTimesResponse struct {
+	Since      time.Time                             `json:"since"`
+	Carrier    string                                `json:"carrier,omitempty"`
+	Facilities map[string][]*analytics.FacilityDwell `json:"facilities"`
+}
+
+//FacilityDwellTimes lists, per carrier, the facilities where shipments sit the longest between scans
+func FacilityDwellTimes(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+	log := logging.FromContext(ctx)
+
+	//analytics only cover the caller's tenant
+	client := auth.ClientFromContext(ctx)
+	if client == nil {
+		return errorResponse(ctx, apiKeyRequiredError())
+	}
+
+	//check for carrier parameter
+	carrier := payload.QueryParam("carrier")
+	if len(carrier) > 0 {
+		token, err := integrations.NormalizeCarrier(carrier)
+		if err != nil {
+			return errorResponse(ctx, models.NewValidationError("carrier", models.FieldCodeUnknownValue, err.Error()))
+		}
+		carrier = token
+	}
+
+	days, err := intQueryParam(payload, "days", defaultDwellDays)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+	if days > maxDwellDays {
+		return errorResponse(ctx, models.NewValidationError("days", models.FieldCodeOutOfRange, fmt.Sprintf("days must be between 1 and %d", maxDwellDays)))
+	}
+	minSamples, err := intQueryParam(payload, "min_samples", defaultDwellMinSamples)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+	limit, err := intQueryParam(payload, "limit", defaultDwellLimit)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	//connect to db
+	databaseConn, err := connectDatabase(ctx)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	events, err := databaseConn.WithLogger(log).TrackingEventManager(client.TenantID).GetScanEvents(ctx, carrier, since)
+	if err != nil {
+		log.Error("query failed", logging.KeyError, err)
+		return errorResponse(ctx, internalError())
+	}
+
+	facilities := analytics.WorstFacilities(events, minSamples, limit)
+
+	//create response
+	successResponse := &facilityDwellTimesResponse{
+		Since:      since,
+		Carrier:    carrier,
+		Facilities: facilities,
+	}
+	return jsonResponse(ctx, http.StatusOK, successResponse)
+}
+
+//intQueryParam parses an optional positive integer query parameter, rejecting anything else with a validation error naming it
+func intQueryParam(payload *models.APIGatewayPayload, name string, defaultValue int) (int, error) {
+	value := payload.QueryParam(name)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, models.NewValidationError(name, models.FieldCodeInvalidType, fmt.Sprintf("%s must be a positive integer", name))
+	} else if parsed < 1 {
+		return 0, models.NewValidationError(name, models.FieldCodeOutOfRange, fmt.Sprintf("%s must be a positive integer", name))
+	}
+	return parsed, nil
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//HTTPHandler adapts an API Gateway handler to net/http by translating the request into an HTTP API (v2) payload and writing the response back
+func HTTPHandler(handler HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := logging.FromContext(r.Context())
+
+		payload, err := PayloadFromRequest(r)
+		if err != nil {
+			log.Warn("invalid request", logging.KeyError, err)
+			WriteError(r.Context(), w, models.NewAPIError(http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Request could not be read"))
+			return
+		}
+
+		response, err := handler(r.Context(), payload)
+		if err != nil {
+			//Lambda reports handler errors as 500s
+			log.Error("handler failed", logging.KeyRequestID, payload.RequestID(), logging.KeyError, err)
+			WriteError(logging.WithRequestID(r.Context(), payload.RequestID()), w, internalError())
+			return
+		}
+
+		err = WriteResponse(w, response)
+		if err != nil {
+			log.Error("response write failed", logging.KeyRequestID, payload.RequestID(), logging.KeyError, err)
+		}
+	})
+}
+
+//PayloadFromRequest builds the payload API Gateway would deliver to a Lambda for this request
+func PayloadFromRequest(r *http.Request) (*models.APIGatewayPayload, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	//API Gateway lower cases header names and joins repeated values with commas
+	headers := map[string]string{}
+	for name, values := range r.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	if len(r.Host) > 0 {
+		headers["host"] = r.Host
+	}
+
+	var queryParams map[string]string
+	if query := r.URL.Query(); len(query) > 0 {
+		queryParams = map[string]string{}
+		for name, values := range query {
+			queryParams[name] = strings.Join(values, ",")
+		}
+	}
+
+	var cookies []string
+	for _, cookie := range r.Cookies() {
+		cookies = append(cookies, cookie.String())
+	}
+
+	sourceIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		sourceIP = host
+	}
+
+	//binary bodies are delivered base64 encoded, as API Gateway does
+	bodyString := string(body)
+	isBase64Encoded := false
+	if !utf8.Valid(body) {
+		bodyString = base64.StdEncoding.EncodeToString(body)
+		isBase64Encoded = true
+	}
+
+	routeKey := r.Method + " " + r.URL.Path
+
+	return &models.APIGatewayPayload{
+		Version:               "2.0",
+		RouteKey:              routeKey,
+		RawPath:               r.URL.Path,
+		RawQueryString:        r.URL.RawQuery,
+		Cookies:               cookies,
+		Headers:               headers,
+		QueryStringParameters: queryParams,
+		Body:                  bodyString,
+		IsBase64Encoded:       isBase64Encoded,
+		RequestContext: &models.RequestContext{
+			DomainName:   r.Host,
+			DomainPrefix: strings.Split(r.Host, ".")[0],
+			HTTP: &models.HTTPInfo{
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Protocol:  r.Proto,
+				SourceIP:  sourceIP,
+				UserAgent: r.UserAgent(),
+			},
+			RequestID: newRequestID(),
+			RouteKey:  routeKey,
+			Stage:     "$default",
+			TimeEpoch: int(time.Now().UnixNano() / int64(time.Millisecond)),
+		},
+	}, nil
+}
+
+//WriteResponse writes an API Gateway response to an HTTP response writer
+func WriteResponse(w http.ResponseWriter, response *models.APIGatewayResponse) error {
+	if response == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	for name, value := range response.Headers {
+		w.Header().Set(name, value)
+	}
+	for name, values := range response.MultiValueHeaders {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	for _, cookie := range response.Cookies {
+		w.Header().Add("Set-Cookie", cookie)
+	}
+
+	body := []byte(response.Body)
+	if response.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(response.Body)
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return err
+		}
+		body = decoded
+	}
+
+	statusCode := response.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	w.WriteHeader(statusCode)
+
+	_, err := w.Write(body)
+	return err
+}
+
+//WriteError writes an API error as JSON, for failures outside of a handler such as requests to unsupported methods
+func WriteError(ctx context.Context, w http.ResponseWriter, apiErr *models.APIError) {
+	response, err := errorResponse(ctx, apiErr)
+	if err != nil {
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+	WriteResponse(w, response)
+}
+
+func newRequestID() string {
+	data := make([]byte, 16)
+	if _, err := rand.Read(data); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(data)
+}
@@ -0,0 +1,63 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/handlers"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	var received *models.APIGatewayPayload
+	echo := func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		received = payload
+		return &models.APIGatewayResponse{
+			StatusCode:      http.StatusCreated,
+			Body:            "aGVsbG8=",
+			IsBase64Encoded: true,
+			Headers: map[string]string{
+				"content-type": "text/plain",
+			},
+		}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/ingest-shipment?carrier=ups&carrier=fedex", strings.NewReader(`{"tracking_code":"1Z"}`))
+	req.Header.Add("X-Custom", "a")
+	req.Header.Add("X-Custom", "b")
+	rec := httptest.NewRecorder()
+
+	handlers.HTTPHandler(echo).ServeHTTP(rec, req)
+
+	if received == nil {
+		t.Fatal("handler was not called")
+	}
+	if received.RawPath != "/ingest-shipment" || received.RequestContext.HTTP.Method != http.MethodPost {
+		t.Errorf("unexpected path or method: %s %s", received.RequestContext.HTTP.Method, received.RawPath)
+	}
+	if received.QueryStringParameters["carrier"] != "ups,fedex" {
+		t.Errorf("query parameters not joined: %q", received.QueryStringParameters["carrier"])
+	}
+	if received.Headers["x-custom"] != "a,b" {
+		t.Errorf("headers not lower cased and joined: %v", received.Headers)
+	}
+	if received.Body != `{"tracking_code":"1Z"}` {
+		t.Errorf("unexpected body %q", received.Body)
+	}
+	if len(received.RequestContext.RequestID) == 0 {
+		t.Error("missing request ID")
+	}
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, rec.Code)
+	}
+	if rec.Body.String() != "hello" {
+		t.Errorf("base64 body not decoded: %q", rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/ingest"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//ingestShipmentResponse is the body IngestShipment answers with
+type ingestShipmentResponse struct {
+	Success       bool     `json:"success"`
+	Carrier       string   `json:"carrier"`
+	ShipmentID    string   `json:"shipment_id"`
+	NewEvents     int      `json:"new_events"`
+	ChangedFields []string `json:"changed_fields"`
+}
+
+//IngestShipment fetches a shipment from Wonderment by carrier and tracking code and saves it along with its tracking history
+func IngestShipment(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+	log := logging.FromContext(ctx)
+
+	//shipments are saved under the caller's tenant
+	client := auth.ClientFromContext(ctx)
+	if client == nil {
+		return errorResponse(ctx, apiKeyRequiredError())
+	}
+
+	//collect parameters from the query string or a JSON or form body
+	params := &struct {
+		Carrier      string `json:"carrier" query:"carrier" form:"carrier"`
+		TrackingCode string `json:"tracking_code" query:"tracking_code" form:"tracking_code" binding:"required"`
+	}{}
+	if err := Bind(payload, params); err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	databaseConn, err := connectDatabase(ctx)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	//fetch shipment from Wonderment and save it along with its tracking history
+	ingestor := ingest.NewIngestor(integrations.NewWondermentAPI(integrations.WondermentBaseURL), databaseConn)
+
+	result, err := ingestor.IngestShipment(ctx, client.TenantID, params.Carrier, params.TrackingCode)
+	var upstreamErr *ingest.UpstreamError
+	if errors.Is(err, integrations.ErrInvalidTrackingNumber) {
+		return errorResponse(ctx, models.NewValidationError("tracking_code", models.FieldCodeInvalid, err.Error()))
+	} else if errors.Is(err, integrations.ErrUnknownCarrier) {
+		return errorResponse(ctx, models.NewValidationError("carrier", models.FieldCodeUnknownValue, err.Error()))
+	} else if errors.As(err, &upstreamErr) {
+		log.Error("upstream request failed", logging.KeyError, err)
+		return errorResponse(ctx, upstreamError(upstreamErr))
+	} else if err != nil {
+		log.Error("ingest failed", logging.KeyError, err)
+		return errorResponse(ctx, internalError())
+	}
+
+	successResponse := &ingestShipmentResponse{
+		Success:       true,
+		Carrier:       result.Carrier,
+		ShipmentID:    result.ShipmentID,
+		NewEvents:     result.NewEventCount,
+		ChangedFields: result.ChangedFields,
+	}
+	return jsonResponse(ctx, http.StatusOK, successResponse)
+}
+
+//upstreamError describes a failed Wonderment request. Timeouts, network failures, throttling and server errors are worth retrying, other rejections are not.
+func upstreamError(err *ingest.UpstreamError) *models.APIError {
+	var statusErr *integrations.UpstreamStatusError
+	if !errors.As(err, &statusErr) {
+		apiErr := models.NewAPIError(http.StatusBadGateway, models.ErrorCodeUpstreamError, "Tracking provider request failed")
+		apiErr.Retryable = true
+		return apiErr
+	}
+
+	switch {
+	case statusErr.StatusCode == http.StatusNotFound:
+		return models.NewAPIError(http.StatusNotFound, models.ErrorCodeNotFound, "Shipment not found by tracking provider")
+	case statusErr.StatusCode == http.StatusTooManyRequests:
+		return models.NewAPIError(http.StatusServiceUnavailable, models.ErrorCodeServiceUnavailable, "Tracking provider is throttling requests")
+	}
+
+	apiErr := models.NewAPIError(http.StatusBadGateway, models.ErrorCodeUpstreamError, fmt.Sprintf("Tracking provider responded with status %d", statusErr.StatusCode))
+	apiErr.Retryable = statusErr.StatusCode >= http.StatusInternalServerError
+	return apiErr
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const (
+	defaultAlertLimit = 50
+	maxAlertLimit     = 500
+)
+
+//listAlertsResponse is the body ListAlerts answers with
+type listAlertsResponse struct {
+	Alerts []*models.Alert `json:"alerts"`
+}
+
+//ListAlerts returns the caller's most recent carrier performance alerts, newest first
+func ListAlerts(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+	log := logging.FromContext(ctx)
+
+	//alerts only cover the caller's tenant
+	client := auth.ClientFromContext(ctx)
+	if client == nil {
+		return errorResponse(ctx, apiKeyRequiredError())
+	}
+
+	//check limit parameter
+	limit, err := intQueryParam(payload, "limit", defaultAlertLimit)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+	if limit > maxAlertLimit {
+		limit = maxAlertLimit
+	}
+
+	//connect to db
+	databaseConn, err := connectDatabase(ctx)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	alerts, err := databaseConn.WithLogger(log).AlertManager().ListAlerts(ctx, client.TenantID, limit)
+	if err != nil {
+		log.Error("query failed", logging.KeyError, err)
+		return errorResponse(ctx, internalError())
+	}
+
+	//create response
+	successResponse := &listAlertsResponse{
+		Alerts: alerts,
+	}
+	return jsonResponse(ctx, http.StatusOK, successResponse)
+}
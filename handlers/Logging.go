@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//WithRequestLogger attaches the API Gateway request ID and a logger tagged with it, the method and path to the context, and logs errors the handler returns.
+//It should wrap every other middleware so their lines are tagged too.
+func WithRequestLogger(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		log := logging.FromContext(ctx).With(
+			logging.KeyRequestID, payload.RequestID(),
+			"method", payload.Method(),
+			"path", payload.RequestPath())
+
+		ctx = logging.WithRequestID(ctx, payload.RequestID())
+		response, err := next(logging.WithLogger(ctx, log), payload)
+		if err != nil {
+			log.Error("handler failed", logging.KeyError, err)
+		}
+		return response, err
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/metrics"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+var handlerLatency = metrics.NewHistogram("handler_duration_ms", "API handler latency per route and response status", metrics.UnitMilliseconds, metrics.LatencyBuckets, "route", "status")
+
+//WithMetrics records the handler's latency and status, then flushes the invocation's metrics as EMF lines when running in Lambda
+func WithMetrics(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		startTime := time.Now()
+
+		response, err := next(ctx, payload)
+
+		status := http.StatusInternalServerError
+		if err == nil && response != nil {
+			status = response.StatusCode
+		}
+		handlerLatency.ObserveSince(startTime, payload.RequestPath(), strconv.Itoa(status))
+
+		if flushErr := metrics.FlushEMF(); flushErr != nil {
+			logging.FromContext(ctx).Warn("metrics flush failed", logging.KeyError, flushErr)
+		}
+
+		return response, err
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//Middleware wraps a handler, e.g. to authenticate or instrument it
+type Middleware func(next HandlerFunc) HandlerFunc
+
+//Chain wraps the handler in the middleware, the first of which runs outermost
+func Chain(handler HandlerFunc, middleware ...Middleware) HandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+//API wraps a handler in the middleware every endpoint shares: request logging, CORS, tracing, metrics, panic recovery, a deadline, timing,
+//authentication with the scope and finally validation against APIDocument. CORS and the deadline are configured from the environment,
+//see CORSConfigFromEnv and DeadlineConfigFromEnv.
+func API(scope string, handler HandlerFunc) HandlerFunc {
+	return Chain(handler,
+		WithRequestLogger,
+		WithCORS(CORSConfigFromEnv(os.Getenv)),
+		WithTracing,
+		WithMetrics,
+		WithRecovery,
+		WithDeadline(deadlineConfigFromEnv()),
+		WithTiming,
+		Authenticated(scope),
+		WithValidation(APIDocument))
+}
+
+//Public wraps a handler in the same middleware as API, without authentication
+func Public(handler HandlerFunc) HandlerFunc {
+	return Chain(handler,
+		WithRequestLogger,
+		WithCORS(CORSConfigFromEnv(os.Getenv)),
+		WithTracing,
+		WithMetrics,
+		WithRecovery,
+		WithDeadline(deadlineConfigFromEnv()),
+		WithTiming,
+		WithValidation(APIDocument))
+}
+
+//Authenticated is RequireAPIKey as a middleware
+func Authenticated(scope string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return RequireAPIKey(scope, next)
+	}
+}
+
+//WithRecovery turns a panic in the handler into a logged 500, so one bad request cannot take down the HTTP server or a warm Lambda
+func WithRecovery(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, payload *models.APIGatewayPayload) (response *models.APIGatewayResponse, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logging.FromContext(ctx).Error("handler panicked", "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+				response, err = errorResponse(ctx, internalError())
+			}
+		}()
+
+		return next(ctx, payload)
+	}
+}
+
+//WithTiming logs the status and execution time of every request the handler answers, and reports the time in a Server-Timing header
+func WithTiming(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		startTime := time.Now()
+
+		response, err := next(ctx, payload)
+		if err != nil || response == nil {
+			return response, err
+		}
+
+		//just some info
+		executionTime := time.Now().Sub(startTime)
+		logging.FromContext(ctx).Info("request completed", "status", response.StatusCode, "execution_time_ms", executionTime)
+
+		if response.Headers == nil {
+			response.Headers = map[string]string{}
+		}
+		response.Headers["server-timing"] = fmt.Sprintf("app;dur=%.1f", float64(executionTime)/float64(time.Millisecond))
+
+		return response, nil
+	}
+}
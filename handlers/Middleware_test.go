@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/handlers"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func okHandler(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+	return &models.APIGatewayResponse{StatusCode: http.StatusOK}, nil
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	tag := func(name string) handlers.Middleware {
+		return func(next handlers.HandlerFunc) handlers.HandlerFunc {
+			return func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+				order = append(order, name)
+				return next(ctx, payload)
+			}
+		}
+	}
+
+	handlers.Chain(okHandler, tag("outer"), tag("inner"))(context.Background(), &models.APIGatewayPayload{})
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("unexpected order %v", order)
+	}
+}
+
+func TestWithRecovery(t *testing.T) {
+	panicking := func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		panic("boom")
+	}
+
+	response, err := handlers.WithRecovery(panicking)(context.Background(), &models.APIGatewayPayload{})
+	if err != nil || response == nil || response.StatusCode != http.StatusInternalServerError {
+		t.Errorf("panic not recovered as a 500: %+v %v", response, err)
+	}
+}
+
+func TestWithCORS(t *testing.T) {
+	config := handlers.CORSConfigFromEnv(func(name string) string {
+		return "https://app.example.com, https://admin.example.com"
+	})
+	handler := handlers.WithCORS(config)(okHandler)
+
+	//preflights are answered without reaching the handler
+	preflight := &models.APIGatewayPayload{
+		Headers:        map[string]string{"origin": "https://app.example.com"},
+		RequestContext: &models.RequestContext{HTTP: &models.HTTPInfo{Method: http.MethodOptions}},
+	}
+	response, _ := handler(context.Background(), preflight)
+	if response.StatusCode != http.StatusNoContent || response.Headers["access-control-allow-origin"] != "https://app.example.com" {
+		t.Errorf("unexpected preflight response %+v", response)
+	}
+	if len(response.Headers["access-control-allow-headers"]) == 0 {
+		t.Error("preflight missing allowed headers")
+	}
+
+	request := &models.APIGatewayPayload{
+		Headers:        map[string]string{"origin": "https://admin.example.com"},
+		RequestContext: &models.RequestContext{HTTP: &models.HTTPInfo{Method: http.MethodGet}},
+	}
+	response, _ = handler(context.Background(), request)
+	if response.StatusCode != http.StatusOK || response.Headers["access-control-allow-origin"] != "https://admin.example.com" {
+		t.Errorf("unexpected response %+v", response)
+	}
+
+	//other origins get no CORS headers
+	request.Headers["origin"] = "https://evil.example.com"
+	response, _ = handler(context.Background(), request)
+	if _, ok := response.Headers["access-control-allow-origin"]; ok {
+		t.Errorf("disallowed origin was allowed: %+v", response.Headers)
+	}
+}
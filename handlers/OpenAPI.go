@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/models"
+	"github.com/elorusso/wonderment-tech-eval/openapi"
+)
+
+//APIDocument describes every endpoint. It is served at /openapi.json and requests are validated against it by WithValidation.
+var APIDocument = newAPIDocument()
+
+//OpenAPISpec returns the OpenAPI document describing the API
+func OpenAPISpec(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+	return jsonResponse(ctx, http.StatusOK, APIDocument)
+}
+
+//WithValidation rejects requests whose parameters or body do not match their operation in the document before they reach the handler.
+//Requests for undocumented operations are passed through.
+func WithValidation(doc *openapi.Document) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+			op := doc.Lookup(payload.Method(), payload.RequestPath())
+			if op == nil {
+				return next(ctx, payload)
+			}
+
+			if err := doc.Validate(op, payload); err != nil {
+				return errorResponse(ctx, err)
+			}
+			return next(ctx, payload)
+		}
+	}
+}
+
+func newAPIDocument() *openapi.Document {
+	doc := openapi.NewDocument(openapi.Info{
+		Title:       "Wonderment shipment analytics",
+		Description: "Ingests shipments tracked by Wonderment and reports carrier performance from their tracking history. Errors are returned as an APIError.",
+		Version:     "1.0.0",
+	})
+	doc.Components.SecuritySchemes["apiKey"] = &openapi.SecurityScheme{
+		Type: "apiKey",
+		Name: apiKeyHeader,
+		In:   "header",
+	}
+	doc.Components.SecuritySchemes["bearer"] = &openapi.SecurityScheme{
+		Type:        "http",
+		Scheme:      "bearer",
+		Description: "The API key as a bearer token",
+	}
+
+	var speedClasses []string
+	for _, class := range integrations.SpeedClasses {
+		speedClasses = append(speedClasses, string(class))
+	}
+	carrier := query("carrier", "Carrier token or alias, such as ups or fedex", openapi.String())
+	speedClass := query("speed_class", "Speed class: "+strings.Join(speedClasses, ", "), openapi.String())
+	originZip := query("origin_zip", "Origin ZIP code, narrowed to its ZIP3", openapi.String())
+	destinationZip := query("destination_zip", "Destination ZIP code, narrowed to its ZIP3", openapi.String())
+
+	//ingest
+	ingestBody := doc.SchemaFor(struct {
+		Carrier      string `json:"carrier,omitempty"`
+		TrackingCode string `json:"tracking_code,omitempty"`
+	}{})
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		op := &openapi.Operation{
+			OperationID: "ingestShipment" + strings.Title(strings.ToLower(method)),
+			Summary:     "Fetch a shipment from Wonderment and save it with its tracking history",
+			Description: "The carrier is detected from the tracking code when omitted. Parameters may be sent in the query string or, for POST, a JSON or form body.",
+			Tags:        []string{"ingest"},
+			Parameters: []*openapi.Parameter{
+				query("carrier", "Carrier token or alias, detected from the tracking code when omitted", openapi.String()),
+				query("tracking_code", "Tracking code, required here or in the body", openapi.String()),
+			},
+			Responses: responses(doc, ingestShipmentResponse{}, http.StatusNotFound, http.StatusBadGateway),
+		}
+		if method == http.MethodPost {
+			op.RequestBody = &openapi.RequestBody{
+				Content: map[string]*openapi.MediaType{
+					openapi.MediaTypeJSON: {Schema: ingestBody},
+					openapi.MediaTypeForm: {Schema: ingestBody},
+				},
+			}
+			op.Responses[strconv.Itoa(http.StatusUnsupportedMediaType)] = errorResponseSpec(doc, http.StatusUnsupportedMediaType)
+		}
+		secure(op, auth.ScopeIngest)
+		doc.AddOperation("/ingest-shipment", method, op)
+	}
+
+	//analytics
+	doc.AddOperation("/average-time-in-transit", http.MethodGet, secure(&openapi.Operation{
+		OperationID: "averageTimeInTransit",
+		Summary:     "Average time in transit of delivered shipments, in milliseconds",
+		Tags:        []string{"analytics"},
+		Parameters: []*openapi.Parameter{
+			carrier,
+			speedClass,
+			query("group_by", "Also break the average down by lane", openapi.String(string(dataAccess.GroupByZone), string(dataAccess.GroupByDistanceBand))),
+		},
+		Responses: responses(doc, averageTimeInTransitResponse{}),
+	}, auth.ScopeAnalytics))
+
+	doc.AddOperation("/facility-dwell-times", http.MethodGet, secure(&openapi.Operation{
+		OperationID: "facilityDwellTimes",
+		Summary:     "Facilities where shipments sit the longest between scans, per carrier",
+		Tags:        []string{"analytics"},
+		Parameters: []*openapi.Parameter{
+			carrier,
+			query("days", fmt.Sprintf("How many days of scans to include, at most %d", maxDwellDays), withDefault(openapi.IntegerBetween(1, maxDwellDays), defaultDwellDays)),
+			query("min_samples", "Minimum scans for a facility to be included", withDefault(openapi.Integer(1), defaultDwellMinSamples)),
+			query("limit", "Facilities to return per carrier", withDefault(openapi.Integer(1), defaultDwellLimit)),
+		},
+		Responses: responses(doc, facilityDwellTimesResponse{}),
+	}, auth.ScopeAnalytics))
+
+	doc.AddOperation("/predict-delivery", http.MethodGet, secure(&openapi.Operation{
+		OperationID: "predictDelivery",
+		Summary:     "Predict the delivery date of a new shipment from delivery history",
+		Tags:        []string{"analytics"},
+		Parameters: []*openapi.Parameter{
+			required(carrier),
+			query("service_level", "Carrier service level token, more specific than a speed class", openapi.String()),
+			speedClass,
+			originZip,
+			destinationZip,
+			query("ship_date", "Date (YYYY-MM-DD) or RFC 3339 timestamp the shipment leaves, defaults to now", openapi.String()),
+			query("confidence", "Share of deliveries the predicted interval should cover", withDefault(openapi.Number(0, 1), defaultForecastConfidence)),
+			query("min_samples", "Minimum delivered shipments a grouping of history needs to be used", withDefault(openapi.Integer(1), defaultForecastMinSamples)),
+		},
+		Responses: responses(doc, analytics.Forecast{}, http.StatusNotFound),
+	}, auth.ScopeAnalytics))
+
+	doc.AddOperation("/compare-carriers", http.MethodGet, secure(&openapi.Operation{
+		OperationID: "compareCarriers",
+		Summary:     "Side by side statistics for every carrier and service level on a lane",
+		Description: "The origin and destination are each required, as a ZIP code or a state.",
+		Tags:        []string{"analytics"},
+		Parameters: []*openapi.Parameter{
+			originZip,
+			query("origin_state", "Origin state", openapi.String()),
+			destinationZip,
+			query("destination_state", "Destination state", openapi.String()),
+			speedClass,
+			query("rank_by", "Metric carriers are ranked by", withDefault(openapi.String(
+				string(analytics.RankByMedianTransit),
+				string(analytics.RankByP90Transit),
+				string(analytics.RankByOnTimeRate),
+				string(analytics.RankByExceptionRate),
+				string(analytics.RankBySampleCount)), string(analytics.RankByMedianTransit))),
+			query("min_samples", "Minimum shipments for a carrier to be ranked", withDefault(openapi.Integer(1), 1)),
+		},
+		Responses: responses(doc, compareCarriersResponse{}),
+	}, auth.ScopeAnalytics))
+
+	doc.AddOperation("/transit-trend", http.MethodGet, secure(&openapi.Operation{
+		OperationID: "transitTrend",
+		Summary:     "Transit times per carrier over time",
+		Tags:        []string{"analytics"},
+		Parameters: []*openapi.Parameter{
+			query("interval", "Bucket size", withDefault(openapi.String(
+				string(analytics.TrendIntervalDay),
+				string(analytics.TrendIntervalWeek),
+				string(analytics.TrendIntervalMonth)), string(analytics.TrendIntervalWeek))),
+			query("start", fmt.Sprintf("Date (YYYY-MM-DD) or RFC 3339 timestamp, defaults to %d intervals before end", defaultTrendBuckets), openapi.String()),
+			query("end", "Date (YYYY-MM-DD) or RFC 3339 timestamp, defaults to now", openapi.String()),
+			carrier,
+			speedClass,
+			query("moving_average", "Buckets to average over", openapi.Integer(1)),
+		},
+		Responses: responses(doc, transitTrendResponse{}),
+	}, auth.ScopeAnalytics))
+
+	doc.AddOperation("/alerts", http.MethodGet, secure(&openapi.Operation{
+		OperationID: "listAlerts",
+		Summary:     "Most recent carrier performance alerts, newest first",
+		Tags:        []string{"alerts"},
+		Parameters: []*openapi.Parameter{
+			query("limit", fmt.Sprintf("Alerts to return, at most %d", maxAlertLimit), withDefault(openapi.Integer(1), defaultAlertLimit)),
+		},
+		Responses: responses(doc, listAlertsResponse{}),
+	}, auth.ScopeAnalytics))
+
+	//audit
+	doc.AddOperation("/shipment-audit-trail", http.MethodGet, secure(&openapi.Operation{
+		OperationID: "shipmentAuditTrail",
+		Summary:     "Ingest attempts for a shipment, newest first",
+		Description: "The shipment is looked up by shipment_id, or by tracking_code and optional carrier.",
+		Tags:        []string{"audit"},
+		Parameters: []*openapi.Parameter{
+			query("shipment_id", "Shipment ID", openapi.String()),
+			query("tracking_code", "Tracking code", openapi.String()),
+			carrier,
+			query("limit", fmt.Sprintf("Entries to return, at most %d", maxAuditLimit), withDefault(openapi.Integer(1), defaultAuditLimit)),
+		},
+		Responses: responses(doc, shipmentAuditTrailResponse{}),
+	}, auth.ScopeAudit))
+
+	doc.AddOperation("/openapi.json", http.MethodGet, &openapi.Operation{
+		OperationID: "openAPISpec",
+		Summary:     "This document",
+		Responses: map[string]*openapi.Response{
+			strconv.Itoa(http.StatusOK): {Description: "OpenAPI document", Content: map[string]*openapi.MediaType{openapi.MediaTypeJSON: {Schema: &openapi.Schema{Type: "object"}}}},
+		},
+	})
+
+	return doc
+}
+
+func query(name string, description string, schema *openapi.Schema) *openapi.Parameter {
+	return &openapi.Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      schema,
+	}
+}
+
+//required copies a shared parameter, marking the copy required
+func required(param *openapi.Parameter) *openapi.Parameter {
+	copied := *param
+	copied.Required = true
+	return &copied
+}
+
+func withDefault(schema *openapi.Schema, value interface{}) *openapi.Schema {
+	schema.Default = value
+	return schema
+}
+
+//secure requires an API key with the scope
+func secure(op *openapi.Operation, scope string) *openapi.Operation {
+	op.RequiredScope = scope
+	op.Security = []map[string][]string{{"apiKey": {}}, {"bearer": {}}}
+	return op
+}
+
+//responses describes a successful response with the body's schema, the errors every authenticated operation can return and any others given
+func responses(doc *openapi.Document, body interface{}, statuses ...int) map[string]*openapi.Response {
+	result := map[string]*openapi.Response{
+		strconv.Itoa(http.StatusOK): {
+			Description: "Success",
+			Content:     map[string]*openapi.MediaType{openapi.MediaTypeJSON: {Schema: doc.SchemaFor(body)}},
+		},
+	}
+	statuses = append([]int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout}, statuses...)
+	for _, status := range statuses {
+		result[strconv.Itoa(status)] = errorResponseSpec(doc, status)
+	}
+	return result
+}
+
+func errorResponseSpec(doc *openapi.Document, status int) *openapi.Response {
+	return &openapi.Response{
+		Description: http.StatusText(status),
+		Content:     map[string]*openapi.MediaType{openapi.MediaTypeJSON: {Schema: doc.SchemaFor(models.APIError{})}},
+	}
+}
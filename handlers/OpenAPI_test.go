@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/handlers"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	response, err := handlers.OpenAPISpec(context.Background(), &models.APIGatewayPayload{})
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected response %+v %v", response, err)
+	}
+
+	doc := &struct {
+		OpenAPI string                            `json:"openapi"`
+		Paths   map[string]map[string]interface{} `json:"paths"`
+	}{}
+	if err := json.Unmarshal([]byte(response.Body), doc); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := []string{"/ingest-shipment", "/average-time-in-transit", "/facility-dwell-times", "/predict-delivery", "/compare-carriers", "/transit-trend", "/alerts", "/shipment-audit-trail", "/openapi.json"}
+	for _, route := range routes {
+		if _, ok := doc.Paths[route]["get"]; !ok {
+			t.Errorf("%s is not documented", route)
+		}
+	}
+	if _, ok := doc.Paths["/ingest-shipment"]["post"]; !ok {
+		t.Error("POST /ingest-shipment is not documented")
+	}
+
+	//every response schema reference resolves
+	for name := range handlers.APIDocument.Components.Schemas {
+		if handlers.APIDocument.Resolve(handlers.APIDocument.Components.Schemas[name]) == nil {
+			t.Errorf("%s does not resolve", name)
+		}
+	}
+}
+
+func TestWithValidation(t *testing.T) {
+	called := false
+	handler := handlers.WithValidation(handlers.APIDocument)(func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		called = true
+		return &models.APIGatewayResponse{StatusCode: http.StatusOK}, nil
+	})
+
+	payload := &models.APIGatewayPayload{
+		RawPath:               "/facility-dwell-times",
+		QueryStringParameters: map[string]string{"days": "many"},
+		RequestContext:        &models.RequestContext{HTTP: &models.HTTPInfo{Method: http.MethodGet}},
+	}
+	response, _ := handler(context.Background(), payload)
+	if called || response.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid request reached the handler: %+v", response)
+	}
+
+	//dwell times load every scan in the window, so it is bounded
+	payload.QueryStringParameters["days"] = "3650"
+	response, _ = handler(context.Background(), payload)
+	if called || response.StatusCode != http.StatusBadRequest || !strings.Contains(response.Body, "between 1 and 90") {
+		t.Errorf("unbounded window reached the handler: %+v", response)
+	}
+
+	payload.QueryStringParameters["days"] = "7"
+	response, _ = handler(context.Background(), payload)
+	if !called || response.StatusCode != http.StatusOK {
+		t.Errorf("valid request rejected: %+v", response)
+	}
+}
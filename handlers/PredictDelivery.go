@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const (
+	defaultForecastMinSamples = 20
+	defaultForecastConfidence = 0.8
+)
+
+//PredictDelivery estimates the delivery date of a new shipment from the tenant's delivery history, independent of the carrier's ETA
+func PredictDelivery(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+	log := logging.FromContext(ctx)
+
+	//forecasts only use the caller's tenant history
+	client := auth.ClientFromContext(ctx)
+	if client == nil {
+		return errorResponse(ctx, apiKeyRequiredError())
+	}
+
+	request := analytics.ForecastRequest{
+		Confidence: defaultForecastConfidence,
+		MinSamples: defaultForecastMinSamples,
+	}
+
+	//check carrier parameter
+	carrier := payload.QueryParam("carrier")
+	if len(carrier) == 0 {
+		return errorResponse(ctx, models.NewValidationError("carrier", models.FieldCodeRequired, "Carrier parameter is required"))
+	}
+	token, err := integrations.NormalizeCarrier(carrier)
+	if err != nil {
+		return errorResponse(ctx, models.NewValidationError("carrier", models.FieldCodeUnknownValue, err.Error()))
+	}
+	request.Carrier = token
+
+	//check service level parameters, a known token is more specific than a speed class
+	if serviceLevel := payload.QueryParam("service_level"); len(serviceLevel) > 0 {
+		request.ServiceLevelToken = strings.ToLower(serviceLevel)
+	}
+	if speedClassVal := payload.QueryParam("speed_class"); len(speedClassVal) > 0 {
+		speedClass, ok := integrations.ParseSpeedClass(speedClassVal)
+		if !ok {
+			return errorResponse(ctx, models.NewValidationError("speed_class", models.FieldCodeUnknownValue, fmt.Sprintf("Unknown speed class %q", speedClassVal)))
+		}
+		request.SpeedClass = string(speedClass)
+	}
+
+	//check lane parameters
+	request.OriginZip3 = integrations.Zip3(integrations.NormalizeZip(payload.QueryParam("origin_zip"), "US"), "US")
+	request.DestinationZip3 = integrations.Zip3(integrations.NormalizeZip(payload.QueryParam("destination_zip"), "US"), "US")
+
+	//check ship date parameter
+	if shipDate := payload.QueryParam("ship_date"); len(shipDate) > 0 {
+		request.ShipDate, err = parseDate(shipDate)
+		if err != nil {
+			return errorResponse(ctx, models.NewValidationError("ship_date", models.FieldCodeInvalid, "ship_date must be a date (YYYY-MM-DD) or RFC 3339 timestamp"))
+		}
+	}
+
+	if confidence := payload.QueryParam("confidence"); len(confidence) > 0 {
+		request.Confidence, err = strconv.ParseFloat(confidence, 64)
+		if err != nil || request.Confidence <= 0 || request.Confidence >= 1 {
+			return errorResponse(ctx, models.NewValidationError("confidence", models.FieldCodeOutOfRange, "confidence must be between 0 and 1"))
+		}
+	}
+	request.MinSamples, err = intQueryParam(payload, "min_samples", defaultForecastMinSamples)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	//connect to db
+	databaseConn, err := connectDatabase(ctx)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	forecast, err := analytics.ForecastDelivery(ctx, databaseConn.WithLogger(log).ShipmentManager(client.TenantID), request)
+	if err == analytics.ErrNoHistory {
+		return errorResponse(ctx, models.NewAPIError(http.StatusNotFound, models.ErrorCodeNotFound, err.Error()))
+	} else if err != nil {
+		log.Error("query failed", logging.KeyError, err)
+		return errorResponse(ctx, internalError())
+	}
+
+	return jsonResponse(ctx, http.StatusOK, forecast)
+}
+
+//parseDate accepts a calendar date or a full RFC 3339 timestamp
+func parseDate(value string) (time.Time, error) {
+	if parsed, err := time.Parse("2006-01-02", value); err == nil {
+		return parsed, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
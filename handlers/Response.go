@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//HandlerFunc is the signature shared by every API Gateway handler, whether it is run by Lambda or by the HTTP server
+type HandlerFunc func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error)
+
+//jsonResponse encodes the body as a JSON response, failing with a logged 500 if it cannot be encoded
+func jsonResponse(ctx context.Context, status int, body interface{}) (*models.APIGatewayResponse, error) {
+	bodyData, err := json.Marshal(body)
+	if err != nil {
+		logging.FromContext(ctx).Error("response encoding failed", logging.KeyError, err)
+		return errorResponse(ctx, internalError())
+	}
+
+	return &models.APIGatewayResponse{
+		StatusCode: status,
+		Body:       string(bodyData),
+		Headers: map[string]string{
+			"content-type": "application/json",
+		},
+	}, nil
+}
+
+//connectDatabase returns a connection from the shared pool, failures are logged and returned as a retryable 503 for errorResponse.
+//The connection is tagged with the context's logger and must not be destroyed.
+func connectDatabase(ctx context.Context) (*dataAccess.SQLConnection, error) {
+	databaseConn, err := dataAccess.SharedConnection(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("database connection failed", logging.KeyError, err)
+		return nil, unavailableError()
+	}
+	return databaseConn, nil
+}
+
+//errorResponse writes the error as a models.APIError tagged with the context's request ID.
+//Errors that are not API errors become an opaque 500 so internal details never reach the client, callers log them first.
+//Server errors once the context's deadline has passed are most likely caused by it, and are reported as a timeout.
+func errorResponse(ctx context.Context, err error) (*models.APIGatewayResponse, error) {
+	var apiErr *models.APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = internalError()
+	}
+	if apiErr.Status >= http.StatusInternalServerError && ctx.Err() == context.DeadlineExceeded {
+		apiErr = timeoutError()
+	}
+
+	body := *apiErr
+	if requestID := logging.RequestIDFromContext(ctx); len(requestID) > 0 {
+		body.RequestID = requestID
+	}
+
+	bodyData, err := json.Marshal(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.APIGatewayResponse{
+		StatusCode: body.Status,
+		Body:       string(bodyData),
+		Headers: map[string]string{
+			"content-type": "application/json",
+		},
+	}, nil
+}
+
+func internalError() *models.APIError {
+	return models.NewAPIError(http.StatusInternalServerError, models.ErrorCodeInternal, "Internal Server Error")
+}
+
+//timeoutError is returned when the request ran out of time, e.g. waiting on a slow query
+func timeoutError() *models.APIError {
+	return models.NewAPIError(http.StatusGatewayTimeout, models.ErrorCodeTimeout, "Request timed out")
+}
+
+//unavailableError is returned when a dependency, such as the database, cannot be reached and a retry may succeed
+func unavailableError() *models.APIError {
+	return models.NewAPIError(http.StatusServiceUnavailable, models.ErrorCodeServiceUnavailable, "Service temporarily unavailable")
+}
+
+func apiKeyRequiredError() *models.APIError {
+	return models.NewAPIError(http.StatusUnauthorized, models.ErrorCodeUnauthorized, "API key is required")
+}
+
+//invalidJSONError describes a body that failed to unmarshal without echoing Go type names back to the client
+func invalidJSONError(err error) *models.APIError {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && len(typeErr.Field) > 0 {
+		apiErr := models.NewValidationError(typeErr.Field, models.FieldCodeInvalidType, fmt.Sprintf("%s must be a %s", typeErr.Field, jsonTypeName(typeErr.Type.Kind().String())))
+		apiErr.Code = models.ErrorCodeInvalidJSON
+		return apiErr
+	} else if errors.As(err, &syntaxErr) {
+		return models.NewAPIError(http.StatusBadRequest, models.ErrorCodeInvalidJSON, fmt.Sprintf("Request body is not valid JSON (offset %d)", syntaxErr.Offset))
+	}
+	return models.NewAPIError(http.StatusBadRequest, models.ErrorCodeInvalidJSON, "Request body is not valid JSON")
+}
+
+func jsonTypeName(kind string) string {
+	switch kind {
+	case "string":
+		return "string"
+	case "bool":
+		return "boolean"
+	case "slice", "array":
+		return "array"
+	case "map", "struct":
+		return "object"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,93 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/handlers"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func serveError(t *testing.T, handler handlers.HandlerFunc, req *http.Request) (*httptest.ResponseRecorder, *models.APIError) {
+	rec := httptest.NewRecorder()
+	handlers.HTTPHandler(handlers.WithRequestLogger(handler)).ServeHTTP(rec, req)
+
+	apiErr := &models.APIError{}
+	if err := json.Unmarshal(rec.Body.Bytes(), apiErr); err != nil {
+		t.Fatalf("error body is not JSON: %q", rec.Body.String())
+	}
+	if len(apiErr.RequestID) == 0 {
+		t.Error("missing request ID")
+	}
+	return rec, apiErr
+}
+
+func TestMissingAPIKeyError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/alerts", nil)
+	rec, apiErr := serveError(t, handlers.RequireAPIKey(auth.ScopeAnalytics, handlers.ListAlerts), req)
+
+	if rec.Code != http.StatusUnauthorized || apiErr.Code != models.ErrorCodeUnauthorized || apiErr.Retryable {
+		t.Errorf("unexpected error %d %+v", rec.Code, apiErr)
+	}
+}
+
+func TestInvalidJSONError(t *testing.T) {
+	withClient := func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		return handlers.IngestShipment(auth.WithClient(ctx, &auth.Client{ClientID: "test", TenantID: "test"}), payload)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/ingest-shipment", strings.NewReader(`{"tracking_code":`))
+	rec, apiErr := serveError(t, withClient, req)
+	if rec.Code != http.StatusBadRequest || apiErr.Code != models.ErrorCodeInvalidJSON {
+		t.Errorf("unexpected error %d %+v", rec.Code, apiErr)
+	}
+	if strings.Contains(apiErr.Message, "unexpected end") {
+		t.Errorf("raw unmarshal error leaked: %q", apiErr.Message)
+	}
+
+	//wrong types are reported against the field
+	req = httptest.NewRequest(http.MethodPost, "/ingest-shipment", strings.NewReader(`{"tracking_code":12}`))
+	rec, apiErr = serveError(t, withClient, req)
+	if rec.Code != http.StatusBadRequest || apiErr.Code != models.ErrorCodeInvalidJSON {
+		t.Errorf("unexpected error %d %+v", rec.Code, apiErr)
+	}
+	if len(apiErr.Details) != 1 || apiErr.Details[0].Field != "tracking_code" || apiErr.Details[0].Code != models.FieldCodeInvalidType {
+		t.Errorf("unexpected details %+v", apiErr.Details)
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	withClient := func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		return handlers.ListAlerts(auth.WithClient(ctx, &auth.Client{ClientID: "test", TenantID: "test"}), payload)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/alerts?limit=0", nil)
+	rec, apiErr := serveError(t, withClient, req)
+	if rec.Code != http.StatusBadRequest || apiErr.Code != models.ErrorCodeValidationFailed {
+		t.Errorf("unexpected error %d %+v", rec.Code, apiErr)
+	}
+	if len(apiErr.Details) != 1 || apiErr.Details[0].Field != "limit" || apiErr.Details[0].Code != models.FieldCodeOutOfRange {
+		t.Errorf("unexpected details %+v", apiErr.Details)
+	}
+}
+
+func TestHandlerFailureError(t *testing.T) {
+	failing := func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		return nil, errors.New("connection reset by peer")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/alerts", nil)
+	rec, apiErr := serveError(t, failing, req)
+	if rec.Code != http.StatusInternalServerError || apiErr.Code != models.ErrorCodeInternal {
+		t.Errorf("unexpected error %d %+v", rec.Code, apiErr)
+	}
+	if strings.Contains(rec.Body.String(), "connection reset") {
+		t.Errorf("internal error leaked: %q", rec.Body.String())
+	}
+}
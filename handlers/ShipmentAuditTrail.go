@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+//shipmentAuditTrailResponse is the body ShipmentAuditTrail answers with
+type shipmentAuditTrailResponse struct {
+	Entries []*models.IngestAuditEntry `json:"entries"`
+}
+
+//ShipmentAuditTrail returns the ingest attempts for a shipment, newest first, looked up by shipment_id or by tracking_code and optional carrier
+func ShipmentAuditTrail(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+	log := logging.FromContext(ctx)
+
+	//audit entries only cover the caller's tenant
+	client := auth.ClientFromContext(ctx)
+	if client == nil {
+		return errorResponse(ctx, apiKeyRequiredError())
+	}
+
+	//check parameters
+	shipmentID := payload.QueryParam("shipment_id")
+	trackingCode := integrations.NormalizeTrackingNumber(payload.QueryParam("tracking_code"))
+	if len(shipmentID) == 0 && len(trackingCode) == 0 {
+		return errorResponse(ctx, models.NewValidationError("shipment_id", models.FieldCodeRequired, "shipment_id or tracking_code is required"))
+	}
+
+	carrier := ""
+	if carrierVal := payload.QueryParam("carrier"); len(carrierVal) > 0 {
+		token, err := integrations.NormalizeCarrier(carrierVal)
+		if err != nil {
+			return errorResponse(ctx, models.NewValidationError("carrier", models.FieldCodeUnknownValue, err.Error()))
+		}
+		carrier = token
+	}
+
+	limit, err := intQueryParam(payload, "limit", defaultAuditLimit)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+
+	//connect to db
+	databaseConn, err := connectDatabase(ctx)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	entries, err := databaseConn.WithLogger(log).IngestAuditManager(client.TenantID).GetAuditTrail(ctx, shipmentID, carrier, trackingCode, limit)
+	if err != nil {
+		log.Error("query failed", logging.KeyError, err)
+		return errorResponse(ctx, internalError())
+	}
+
+	//create response
+	successResponse := &shipmentAuditTrailResponse{
+		Entries: entries,
+	}
+	return jsonResponse(ctx, http.StatusOK, successResponse)
+}
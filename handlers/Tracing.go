@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+	"github.com/elorusso/wonderment-tech-eval/tracing"
+)
+
+//WithTracing wraps the handler in a server span, continuing the caller's trace when the request has a traceparent header,
+//and tags the context's logger with the trace ID
+func WithTracing(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+		ctx = tracing.WithRemoteParent(ctx, payload.Header(tracing.TraceparentHeader))
+		ctx, span := tracing.Start(ctx, payload.Method()+" "+payload.RequestPath(), tracing.KindServer)
+		defer span.Finish()
+
+		span.SetAttribute("http.method", payload.Method())
+		span.SetAttribute("http.route", payload.RequestPath())
+		span.SetAttribute(logging.KeyRequestID, payload.RequestID())
+
+		ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("trace_id", span.TraceID))
+
+		response, err := next(ctx, payload)
+
+		status := http.StatusInternalServerError
+		if err == nil && response != nil {
+			status = response.StatusCode
+		}
+		span.SetAttribute("http.status_code", status)
+		span.RecordError(err)
+
+		return response, err
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/analytics"
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const (
+	defaultTrendBuckets = 12
+)
+
+//transitTrendResponse is the body TransitTrend answers with
+type transitTrendResponse struct {
+	Interval      analytics.TrendInterval         `json:"interval"`
+	Start         time.Time                       `json:"start"`
+	End           time.Time                       `json:"end"`
+	SpeedClass    string                          `json:"speed_class,omitempty"`
+	MovingAverage int                             `json:"moving_average,omitempty"`
+	Series        map[string][]*models.TrendPoint `json:"series"`
+}
+
+//TransitTrend returns transit time aggregates per carrier bucketed by day, week or month over a date range
+func TransitTrend(ctx context.Context, payload *models.APIGatewayPayload) (*models.APIGatewayResponse, error) {
+	log := logging.FromContext(ctx)
+
+	//trends only cover the caller's tenant
+	client := auth.ClientFromContext(ctx)
+	if client == nil {
+		return errorResponse(ctx, apiKeyRequiredError())
+	}
+
+	//check interval parameter
+	interval := analytics.TrendIntervalWeek
+	if intervalVal := payload.QueryParam("interval"); len(intervalVal) > 0 {
+		var err error
+		interval, err = analytics.ParseTrendInterval(intervalVal)
+		if err != nil {
+			return errorResponse(ctx, models.NewValidationError("interval", models.FieldCodeUnknownValue, err.Error()))
+		}
+	}
+
+	//check date range parameters, defaulting to the last twelve intervals
+	end := time.Now().UTC()
+	if endVal := payload.QueryParam("end"); len(endVal) > 0 {
+		var err error
+		end, err = parseDate(endVal)
+		if err != nil {
+			return errorResponse(ctx, models.NewValidationError("end", models.FieldCodeInvalid, "end must be a date (YYYY-MM-DD) or RFC 3339 timestamp"))
+		}
+	}
+	start := analytics.TruncateToBucket(end, interval)
+	for i := 1; i < defaultTrendBuckets; i++ {
+		start = analytics.TruncateToBucket(start.Add(-time.Second), interval)
+	}
+	if startVal := payload.QueryParam("start"); len(startVal) > 0 {
+		var err error
+		start, err = parseDate(startVal)
+		if err != nil {
+			return errorResponse(ctx, models.NewValidationError("start", models.FieldCodeInvalid, "start must be a date (YYYY-MM-DD) or RFC 3339 timestamp"))
+		}
+	}
+
+	buckets, err := analytics.Buckets(start, end, interval)
+	if err != nil {
+		return errorResponse(ctx, models.NewValidationError("start", models.FieldCodeOutOfRange, err.Error()))
+	}
+
+	//check filters
+	filter := models.TransitFilter{}
+	var carriers []string
+	if carrier := payload.QueryParam("carrier"); len(carrier) > 0 {
+		token, err := integrations.NormalizeCarrier(carrier)
+		if err != nil {
+			return errorResponse(ctx, models.NewValidationError("carrier", models.FieldCodeUnknownValue, err.Error()))
+		}
+		filter.Carrier = token
+		carriers = append(carriers, token)
+	}
+	if speedClassVal := payload.QueryParam("speed_class"); len(speedClassVal) > 0 {
+		speedClass, ok := integrations.ParseSpeedClass(speedClassVal)
+		if !ok {
+			return errorResponse(ctx, models.NewValidationError("speed_class", models.FieldCodeUnknownValue, fmt.Sprintf("Unknown speed class %q", speedClassVal)))
+		}
+		filter.SpeedClass = string(speedClass)
+	}
+
+	movingAverage := 0
+	if len(payload.QueryParam("moving_average")) > 0 {
+		movingAverage, err = intQueryParam(payload, "moving_average", 0)
+		if err != nil {
+			return errorResponse(ctx, err)
+		}
+	}
+
+	//connect to db
+	databaseConn, err := connectDatabase(ctx)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	//query from the first bucket start so partial leading buckets are complete
+	points, err := databaseConn.WithLogger(log).ShipmentManager(client.TenantID).GetTransitTrend(ctx, filter, string(interval), buckets[0], end)
+	if err != nil {
+		log.Error("query failed", logging.KeyError, err)
+		return errorResponse(ctx, internalError())
+	}
+
+	//create response
+	successResponse := &transitTrendResponse{
+		Interval:      interval,
+		Start:         buckets[0],
+		End:           end,
+		SpeedClass:    filter.SpeedClass,
+		MovingAverage: movingAverage,
+		Series:        analytics.BuildTrendSeries(points, buckets, carriers, movingAverage),
+	}
+	return jsonResponse(ctx, http.StatusOK, successResponse)
+}
@@ -0,0 +1,57 @@
+package ingest
+
+import (
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+//ChangedFields lists the stored shipment columns that differ from what upstream now reports, in a fixed order.
+//It returns an empty list for a shipment that has not been saved before.
+func ChangedFields(previous *models.ShipmentSnapshot, shipment *integrations.WondermentShipment) []string {
+	changed := []string{}
+	if previous == nil || shipment == nil {
+		return changed
+	}
+
+	serviceLevel := shipment.ServiceLevel
+	if serviceLevel == nil {
+		serviceLevel = &integrations.ServiceLevel{}
+	}
+	token := ""
+	if serviceLevel.Token != nil {
+		token = *serviceLevel.Token
+	}
+	if previous.ServiceLevelToken != token {
+		changed = append(changed, "service_level_token")
+	}
+
+	speedClass := ""
+	if info, ok := integrations.ClassifyServiceLevel(shipment.Carrier, serviceLevel); ok {
+		speedClass = string(info.SpeedClass)
+	}
+	if previous.SpeedClass != speedClass {
+		changed = append(changed, "speed_class")
+	}
+
+	if !sameTime(previous.ETA, shipment.ETA) {
+		changed = append(changed, "eta")
+	}
+	if !sameTime(previous.OriginalETA, shipment.OriginalETA) {
+		changed = append(changed, "original_eta")
+	}
+	if previous.Test != shipment.Test {
+		changed = append(changed, "test")
+	}
+
+	return changed
+}
+
+//sameTime compares a stored time, nil when unset, with an upstream time, zero when unset, to the second as they are stored
+func sameTime(stored *time.Time, upstream time.Time) bool {
+	if stored == nil || stored.IsZero() {
+		return upstream.IsZero()
+	}
+	return !upstream.IsZero() && stored.Truncate(time.Second).Equal(upstream.Truncate(time.Second))
+}
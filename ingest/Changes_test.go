@@ -0,0 +1,54 @@
+package ingest_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/ingest"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func TestChangedFields(t *testing.T) {
+	eta := time.Date(2026, 3, 4, 17, 0, 0, 0, time.UTC)
+	token := "ups_ground"
+
+	shipment := func() *integrations.WondermentShipment {
+		return &integrations.WondermentShipment{
+			Carrier:      "ups",
+			ServiceLevel: &integrations.ServiceLevel{Token: &token},
+			ETA:          eta.Add(300 * time.Millisecond),
+		}
+	}
+	previous := &models.ShipmentSnapshot{
+		ServiceLevelToken: "ups_ground",
+		SpeedClass:        "ground",
+		ETA:               &eta,
+	}
+
+	if changed := ingest.ChangedFields(nil, shipment()); len(changed) != 0 {
+		t.Errorf("expected no changes for a new shipment, got %v", changed)
+	}
+	if changed := ingest.ChangedFields(previous, shipment()); len(changed) != 0 {
+		t.Errorf("expected no changes, got %v", changed)
+	}
+
+	revised := shipment()
+	revised.ETA = eta.Add(24 * time.Hour)
+	revised.OriginalETA = eta
+	revised.Test = true
+	expected := []string{"eta", "original_eta", "test"}
+	if changed := ingest.ChangedFields(previous, revised); !reflect.DeepEqual(changed, expected) {
+		t.Errorf("expected %v, got %v", expected, changed)
+	}
+
+	upgraded := shipment()
+	nextDay := "ups_next_day_air"
+	upgraded.ServiceLevel = &integrations.ServiceLevel{Token: &nextDay}
+	upgraded.ETA = time.Time{}
+	expected = []string{"service_level_token", "speed_class", "eta"}
+	if changed := ingest.ChangedFields(previous, upgraded); !reflect.DeepEqual(changed, expected) {
+		t.Errorf("expected %v, got %v", expected, changed)
+	}
+}
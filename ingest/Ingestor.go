@@ -0,0 +1,263 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/metrics"
+	"github.com/elorusso/wonderment-tech-eval/models"
+	"github.com/elorusso/wonderment-tech-eval/tracing"
+)
+
+//auditTimeout bounds writing the audit entry, which outlives the request's deadline
+const auditTimeout = 500 * time.Millisecond
+
+var (
+	ingestOutcomes = metrics.NewCounter("ingest_total", "Shipment ingests per carrier by outcome: success, validation_error, upstream_error or database_error", "carrier", "outcome")
+	ingestEvents   = metrics.NewHistogram("ingest_events", "Tracking events saved per successful ingest", metrics.UnitCount, metrics.CountBuckets, "carrier")
+)
+
+//Result describes the outcome of a single shipment ingest
+type Result struct {
+	ShipmentID    string
+	Carrier       string //detected from the tracking code when the caller omitted it
+	EventCount    int
+	NewEventCount int      //events not saved by an earlier ingest
+	ChangedFields []string //stored shipment fields upstream revised since the last ingest
+	TimeInTransit int      //milliseconds, zero if the shipment has not been delivered
+}
+
+//UpstreamError wraps failures fetching the shipment from Wonderment, so callers can tell them apart from database failures
+type UpstreamError struct {
+	Err error
+}
+
+func (err *UpstreamError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *UpstreamError) Unwrap() error {
+	return err.Err
+}
+
+//Ingestor fetches shipments from Wonderment and saves them along with their tracking history
+type Ingestor struct {
+	api  *integrations.WondermentAPI
+	conn *dataAccess.SQLConnection
+}
+
+func NewIngestor(api *integrations.WondermentAPI, conn *dataAccess.SQLConnection) *Ingestor {
+	return &Ingestor{
+		api:  api,
+		conn: conn,
+	}
+}
+
+//IngestShipment fetches the shipment for the carrier and tracking code, saves it and its tracking events under the tenant, and records its time in transit once delivered.
+//The carrier is normalized to its registry token, or inferred from the tracking code when empty. Unknown carriers and malformed tracking codes are rejected
+//with errors wrapping integrations.ErrUnknownCarrier and integrations.ErrInvalidTrackingNumber before calling upstream, and upstream failures are returned as an *UpstreamError.
+//Log lines are written to the context's logger, tagged with the carrier, tracking code and, once saved, the shipment ID, and spans are started under the context's span.
+//Every attempt with a tenant is appended to the ingest audit trail under the context's client and request ID.
+func (ing Ingestor) IngestShipment(ctx context.Context, tenantID string, carrier string, trackingCode string) (result *Result, err error) {
+	ctx, span := tracing.Start(ctx, "IngestShipment", tracing.KindInternal)
+
+	//record the outcome, labelled with the stage that failed, once the carrier is known
+	metricCarrier := "unknown"
+	stage := "validation"
+	audit := &models.IngestAuditEntry{}
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = stage + "_error"
+		} else {
+			ingestEvents.Observe(float64(result.EventCount), metricCarrier)
+		}
+		ingestOutcomes.Inc(metricCarrier, outcome)
+
+		span.SetAttribute("outcome", outcome)
+		span.RecordError(err)
+		span.Finish()
+
+		if len(tenantID) != 0 {
+			audit.Carrier = carrier
+			audit.TrackingCode = trackingCode
+			audit.Outcome = outcome
+			if err != nil {
+				message := err.Error()
+				audit.Error = &message
+			}
+			ing.recordAudit(ctx, tenantID, audit)
+		}
+	}()
+
+	if len(tenantID) == 0 {
+		return nil, errors.New("Invalid tenant")
+	}
+
+	//validate before spending upstream quota
+	trackingCode = integrations.NormalizeTrackingNumber(trackingCode)
+	if len(carrier) == 0 {
+		detected, err := integrations.DetectCarrier(trackingCode)
+		if err != nil {
+			return nil, err
+		}
+		carrier = detected
+	} else {
+		token, err := integrations.NormalizeCarrier(carrier)
+		if err != nil {
+			return nil, err
+		}
+		carrier = token
+	}
+	if err := integrations.ValidateTrackingNumber(carrier, trackingCode); err != nil {
+		return nil, err
+	}
+	metricCarrier = carrier
+	span.SetAttribute(logging.KeyCarrier, carrier)
+	span.SetAttribute(logging.KeyTrackingCode, trackingCode)
+
+	log := logging.FromContext(ctx).With(logging.KeyCarrier, carrier, logging.KeyTrackingCode, trackingCode)
+	ctx = logging.WithLogger(ctx, log)
+
+	//fetch shipment info from Wonderment
+	stage = "upstream"
+	wonderShipment, err := ing.api.LimitedTrackingSerice(ctx, carrier, trackingCode)
+	var statusErr *integrations.UpstreamStatusError
+	if errors.As(err, &statusErr) {
+		audit.UpstreamStatus = &statusErr.StatusCode
+	} else if err == nil {
+		status := http.StatusOK
+		audit.UpstreamStatus = &status
+	}
+	if err != nil {
+		return nil, &UpstreamError{Err: err}
+	}
+
+	//store the canonical token rather than whatever spelling upstream echoes back
+	wonderShipment.Carrier = carrier
+
+	//compare with the stored shipment to audit what upstream revised
+	stage = "database"
+	previous, err := ing.conn.WithLogger(log).ShipmentManager(tenantID).GetShipmentSnapshot(ctx, carrier, wonderShipment.TrackingNumber)
+	if err != nil {
+		return nil, err
+	}
+	audit.ChangedFields = ChangedFields(previous, wonderShipment)
+
+	//save shipment, refreshing revised fields on conflict
+	_, insertSpan := tracing.Start(ctx, "InsertShipment", tracing.KindInternal)
+	shipmentID, err := ing.conn.WithLogger(log).ShipmentManager(tenantID).InsertShipment(ctx, wonderShipment)
+	insertSpan.RecordError(err)
+	insertSpan.Finish()
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttribute(logging.KeyShipmentID, shipmentID)
+	audit.ShipmentID = &shipmentID
+
+	log = log.With(logging.KeyShipmentID, shipmentID)
+	conn := ing.conn.WithLogger(log)
+	shipmentManager := conn.ShipmentManager(tenantID)
+
+	firstTransitTime := time.Now()
+	deliveryTime := time.Time{}
+
+	for _, event := range wonderShipment.TrackingHistory {
+		if strings.ToLower(event.Status) == "transit" && event.StatusDate.Before(firstTransitTime) {
+			firstTransitTime = event.StatusDate
+		} else if strings.ToLower(event.Status) == "delivered" {
+			//assuming there is only one delivery event
+			deliveryTime = event.StatusDate
+		}
+	}
+
+	//save tracking events in bulk, skipping those saved by an earlier ingest
+	eventsCtx, eventsSpan := tracing.Start(ctx, "InsertTrackingEvents", tracing.KindInternal)
+	eventsSpan.SetAttribute("event_count", len(wonderShipment.TrackingHistory))
+	counts, err := conn.TrackingEventManager(tenantID).InsertTrackingEvents(eventsCtx, wonderShipment.TrackingHistory, shipmentID)
+	audit.NewEvents = counts.Inserted
+	eventsSpan.SetAttribute("new_event_count", counts.Inserted)
+	eventsSpan.SetAttribute("duplicate_event_count", counts.Duplicates)
+	eventsSpan.RecordError(err)
+	eventsSpan.Finish()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("saved tracking events", "event_count", len(wonderShipment.TrackingHistory), "new_event_count", counts.Inserted, "duplicate_event_count", counts.Duplicates, "changed_fields", audit.ChangedFields)
+
+	result = &Result{
+		ShipmentID:    shipmentID,
+		Carrier:       carrier,
+		EventCount:    len(wonderShipment.TrackingHistory),
+		NewEventCount: audit.NewEvents,
+		ChangedFields: audit.ChangedFields,
+	}
+
+	//calculate time in transit, if delivered
+	if !deliveryTime.IsZero() && firstTransitTime != time.Now() {
+		timeInTransit := deliveryTime.Sub(firstTransitTime) //nanoseconds
+
+		result.TimeInTransit = int(timeInTransit / 1000000) //save in milliseconds
+		_, updateSpan := tracing.Start(ctx, "UpdateTransitTimeForShipment", tracing.KindInternal)
+		err = shipmentManager.UpdateTransitTimeForShipment(ctx, shipmentID, result.TimeInTransit, deliveryTime)
+		updateSpan.RecordError(err)
+		updateSpan.Finish()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+//recordAudit appends the attempt to the tenant's audit trail. Failures are logged rather than failing the ingest.
+//The entry is written even when the ingest ran out of time, so it gets its own short deadline instead of the request's.
+func (ing Ingestor) recordAudit(ctx context.Context, tenantID string, audit *models.IngestAuditEntry) {
+	log := logging.FromContext(ctx)
+	auditCtx, cancel := context.WithTimeout(detach(ctx), auditTimeout)
+	defer cancel()
+
+	audit.ClientID = "unknown"
+	if client := auth.ClientFromContext(ctx); client != nil {
+		audit.ClientID = client.ClientID
+		if len(client.KeyID) != 0 {
+			audit.KeyID = &client.KeyID
+		}
+	}
+	if requestID := logging.RequestIDFromContext(ctx); len(requestID) != 0 {
+		audit.RequestID = &requestID
+	}
+
+	if _, err := ing.conn.WithLogger(log).IngestAuditManager(tenantID).InsertAuditEntry(auditCtx, audit); err != nil {
+		log.Error("audit entry failed", logging.KeyError, err)
+	}
+}
+
+//detachedContext keeps a context's values, such as its logger and request ID, without its deadline or cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
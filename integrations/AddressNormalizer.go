@@ -0,0 +1,185 @@
+package integrations
+
+import (
+	"strings"
+	"unicode"
+)
+
+//NormalizedAddress is an Address in canonical form, used to group shipments into lanes
+type NormalizedAddress struct {
+	City    string //title cased, whitespace collapsed
+	State   string //USPS two letter code for US addresses, upper cased otherwise
+	Zip     string //five digit ZIP for US addresses, upper cased otherwise
+	Zip3    string //first three digits of a US ZIP, or the forward sortation area of a Canadian postal code
+	Country string //ISO 3166-1 alpha-2 code
+}
+
+var usStateNames = map[string]string{
+	"alabama": "AL", "alaska": "AK", "arizona": "AZ", "arkansas": "AR", "california": "CA",
+	"colorado": "CO", "connecticut": "CT", "delaware": "DE", "district of columbia": "DC", "washington dc": "DC",
+	"florida": "FL", "georgia": "GA", "hawaii": "HI", "idaho": "ID", "illinois": "IL",
+	"indiana": "IN", "iowa": "IA", "kansas": "KS", "kentucky": "KY", "louisiana": "LA",
+	"maine": "ME", "maryland": "MD", "massachusetts": "MA", "michigan": "MI", "minnesota": "MN",
+	"mississippi": "MS", "missouri": "MO", "montana": "MT", "nebraska": "NE", "nevada": "NV",
+	"new hampshire": "NH", "new jersey": "NJ", "new mexico": "NM", "new york": "NY", "north carolina": "NC",
+	"north dakota": "ND", "ohio": "OH", "oklahoma": "OK", "oregon": "OR", "pennsylvania": "PA",
+	"rhode island": "RI", "south carolina": "SC", "south dakota": "SD", "tennessee": "TN", "texas": "TX",
+	"utah": "UT", "vermont": "VT", "virginia": "VA", "washington": "WA", "west virginia": "WV",
+	"wisconsin": "WI", "wyoming": "WY", "puerto rico": "PR", "guam": "GU", "us virgin islands": "VI",
+	"virgin islands": "VI", "american samoa": "AS", "northern mariana islands": "MP",
+	"armed forces americas": "AA", "armed forces europe": "AE", "armed forces pacific": "AP",
+
+	//traditional and AP style abbreviations
+	"ala": "AL", "ariz": "AZ", "ark": "AR", "calif": "CA", "cal": "CA", "colo": "CO",
+	"conn": "CT", "del": "DE", "fla": "FL", "ga": "GA", "ill": "IL", "ind": "IN",
+	"kan": "KS", "kans": "KS", "ky": "KY", "la": "LA", "md": "MD", "mass": "MA",
+	"mich": "MI", "minn": "MN", "miss": "MS", "mo": "MO", "mont": "MT", "neb": "NE",
+	"nebr": "NE", "nev": "NV", "nh": "NH", "nj": "NJ", "nm": "NM", "ny": "NY",
+	"nc": "NC", "nd": "ND", "okla": "OK", "ore": "OR", "oreg": "OR", "pa": "PA",
+	"penn": "PA", "penna": "PA", "ri": "RI", "sc": "SC", "sd": "SD", "tenn": "TN",
+	"tex": "TX", "vt": "VT", "va": "VA", "wash": "WA", "wva": "WV", "wis": "WI",
+	"wisc": "WI", "wyo": "WY", "dc": "DC",
+}
+
+var usStateCodes = buildUSStateCodes()
+
+func buildUSStateCodes() map[string]bool {
+	codes := map[string]bool{}
+	for _, code := range usStateNames {
+		codes[code] = true
+	}
+	return codes
+}
+
+var countryNames = map[string]string{
+	"us": "US", "usa": "US", "united states": "US", "united states of america": "US", "america": "US",
+	"ca": "CA", "can": "CA", "canada": "CA",
+	"mx": "MX", "mex": "MX", "mexico": "MX",
+	"gb": "GB", "gbr": "GB", "uk": "GB", "united kingdom": "GB", "great britain": "GB", "england": "GB",
+	"de": "DE", "deu": "DE", "germany": "DE",
+	"fr": "FR", "fra": "FR", "france": "FR",
+	"au": "AU", "aus": "AU", "australia": "AU",
+	"jp": "JP", "jpn": "JP", "japan": "JP",
+	"cn": "CN", "chn": "CN", "china": "CN",
+	"in": "IN", "ind": "IN", "india": "IN",
+	"br": "BR", "bra": "BR", "brazil": "BR",
+	"it": "IT", "ita": "IT", "italy": "IT",
+	"es": "ES", "esp": "ES", "spain": "ES",
+	"nl": "NL", "nld": "NL", "netherlands": "NL",
+}
+
+//addressKey lower cases and drops punctuation so "Calif." and "calif" match
+func addressKey(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, value)
+	return strings.Join(strings.Fields(value), " ")
+}
+
+//NormalizeCountry returns the ISO alpha-2 code for a country name or code, upper casing unrecognized values
+func NormalizeCountry(country string) string {
+	key := addressKey(country)
+	if code, ok := countryNames[key]; ok {
+		return code
+	}
+	return strings.ToUpper(strings.TrimSpace(country))
+}
+
+//NormalizeState returns the USPS code for US states, upper casing other values
+func NormalizeState(state string, country string) string {
+	if len(country) == 0 || country == "US" {
+		key := addressKey(state)
+		if code, ok := usStateNames[key]; ok {
+			return code
+		}
+		if code := strings.ToUpper(key); usStateCodes[code] {
+			return code
+		}
+	}
+	return strings.ToUpper(strings.TrimSpace(state))
+}
+
+//NormalizeZip returns the five digit ZIP for US addresses, restoring leading zeros lost in spreadsheets, and the upper cased postal code otherwise
+func NormalizeZip(zip string, country string) string {
+	zip = strings.ToUpper(strings.TrimSpace(zip))
+	if country != "US" {
+		return strings.Join(strings.Fields(zip), " ")
+	}
+
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, strings.SplitN(zip, "-", 2)[0])
+
+	switch {
+	case len(digits) >= 5:
+		return digits[:5]
+	case len(digits) >= 3:
+		return strings.Repeat("0", 5-len(digits)) + digits
+	default:
+		return digits
+	}
+}
+
+//Zip3 returns the three digit prefix of a US ZIP, or the forward sortation area of a Canadian postal code
+func Zip3(zip string, country string) string {
+	if (country == "US" && len(zip) == 5) || (country == "CA" && len(zip) >= 3) {
+		return zip[:3]
+	}
+	return ""
+}
+
+//NormalizeCity title cases the city and collapses whitespace
+func NormalizeCity(city string) string {
+	words := strings.Fields(strings.Trim(city, " ,."))
+	for i, word := range words {
+		words[i] = titleWord(word)
+	}
+	return strings.Join(words, " ")
+}
+
+func titleWord(word string) string {
+	runes := []rune(strings.ToLower(word))
+	capitalize := true
+	for i, r := range runes {
+		if capitalize && unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+		}
+		capitalize = r == '-' || r == '\'' || r == '.'
+	}
+	return string(runes)
+}
+
+//NormalizeAddress canonicalizes every field of the address. Addresses without a country are assumed to be in the US when their state is a US state.
+func NormalizeAddress(address *Address) NormalizedAddress {
+	if address == nil {
+		return NormalizedAddress{}
+	}
+
+	country := NormalizeCountry(stringValue(address.Country))
+	if len(country) == 0 && usStateCodes[NormalizeState(stringValue(address.State), "")] {
+		country = "US"
+	}
+
+	zip := NormalizeZip(stringValue(address.Zip), country)
+
+	return NormalizedAddress{
+		City:    NormalizeCity(stringValue(address.City)),
+		State:   NormalizeState(stringValue(address.State), country),
+		Zip:     zip,
+		Zip3:    Zip3(zip, country),
+		Country: country,
+	}
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
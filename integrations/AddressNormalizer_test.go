@@ -0,0 +1,43 @@
+package integrations_test
+
+import (
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	stringPtr := func(s string) *string { return &s }
+
+	cases := []struct {
+		address  *integrations.Address
+		expected integrations.NormalizedAddress
+	}{
+		{
+			&integrations.Address{City: stringPtr("  san   FRANCISCO "), State: stringPtr("Calif."), Zip: stringPtr("94107-1234"), Country: stringPtr("USA")},
+			integrations.NormalizedAddress{City: "San Francisco", State: "CA", Zip: "94107", Zip3: "941", Country: "US"},
+		},
+		{
+			&integrations.Address{City: stringPtr("boston"), State: stringPtr("massachusetts"), Zip: stringPtr("2134")},
+			integrations.NormalizedAddress{City: "Boston", State: "MA", Zip: "02134", Zip3: "021", Country: "US"},
+		},
+		{
+			&integrations.Address{City: stringPtr("WINSTON-SALEM"), State: stringPtr("nc"), Zip: stringPtr("271019999"), Country: stringPtr("United States")},
+			integrations.NormalizedAddress{City: "Winston-Salem", State: "NC", Zip: "27101", Zip3: "271", Country: "US"},
+		},
+		{
+			&integrations.Address{City: stringPtr("toronto"), State: stringPtr("on"), Zip: stringPtr("m5v 3l9"), Country: stringPtr("Canada")},
+			integrations.NormalizedAddress{City: "Toronto", State: "ON", Zip: "M5V 3L9", Zip3: "M5V", Country: "CA"},
+		},
+		{
+			nil,
+			integrations.NormalizedAddress{},
+		},
+	}
+
+	for _, c := range cases {
+		if normalized := integrations.NormalizeAddress(c.address); normalized != c.expected {
+			t.Errorf("expected %+v, got %+v", c.expected, normalized)
+		}
+	}
+}
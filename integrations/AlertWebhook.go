@@ -0,0 +1,86 @@
+package integrations
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const (
+	//AlertSignatureHeader carries the hex HMAC-SHA256 of the request body when the webhook has a secret
+	AlertSignatureHeader = "x-wonderment-signature"
+
+	alertWebhookTimeout = 10 * time.Second
+)
+
+//AlertWebhook posts alerts as JSON to a receiver URL
+type AlertWebhook struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+//alertWebhookPayload is the JSON body sent to the receiver
+type alertWebhookPayload struct {
+	Alerts []*models.Alert `json:"alerts"`
+}
+
+//NewAlertWebhook returns a webhook for the URL. An empty secret sends unsigned requests.
+func NewAlertWebhook(url string, secret string) *AlertWebhook {
+	return &AlertWebhook{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: alertWebhookTimeout},
+	}
+}
+
+//Send posts the alerts in a single request and fails unless the receiver answers with a 2xx status
+func (hook AlertWebhook) Send(alerts []*models.Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(alertWebhookPayload{Alerts: alerts})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, hook.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("content-type", "application/json")
+	if len(hook.secret) != 0 {
+		req.Header.Set(AlertSignatureHeader, SignAlertPayload(hook.secret, body))
+	}
+
+	//execute request
+	resp, err := hook.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Alert webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+//SignAlertPayload returns the hex HMAC-SHA256 of the body, for receivers to verify AlertSignatureHeader
+func SignAlertPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,56 @@
+package integrations_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func TestAlertWebhookSend(t *testing.T) {
+	var received struct {
+		Alerts []*models.Alert `json:"alerts"`
+	}
+	var signature, expected string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		signature = r.Header.Get(integrations.AlertSignatureHeader)
+		expected = integrations.SignAlertPayload("secret", body)
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook := integrations.NewAlertWebhook(server.URL, "secret")
+	err := hook.Send([]*models.Alert{{TenantID: "t1", Carrier: "ups", Metric: models.AlertMetricExceptionRate, RecentValue: 0.2}})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if len(received.Alerts) != 1 || received.Alerts[0].Carrier != "ups" {
+		t.Errorf("unexpected payload %+v", received)
+	}
+	if len(signature) == 0 || signature != expected {
+		t.Errorf("signature %q does not match %q", signature, expected)
+	}
+}
+
+func TestAlertWebhookSendFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	hook := integrations.NewAlertWebhook(server.URL, "")
+	if err := hook.Send([]*models.Alert{{Carrier: "ups"}}); err == nil {
+		t.Error("expected an error for a 500 response")
+	}
+	if err := hook.Send(nil); err != nil {
+		t.Errorf("expected no request without alerts, got %v", err)
+	}
+}
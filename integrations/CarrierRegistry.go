@@ -0,0 +1,145 @@
+package integrations
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+//ErrUnknownCarrier is wrapped when a carrier name does not match any registered carrier or alias
+var ErrUnknownCarrier = errors.New("Unknown carrier")
+
+//Carrier describes a carrier Wonderment can track
+type Carrier struct {
+	Token         string   //canonical token, as sent to Wonderment and stored on shipments
+	DisplayName   string   //human readable name
+	Aliases       []string //other names callers use for the carrier
+	ServiceLevels []string //service level tokens the carrier offers
+}
+
+var carrierRegistry = []*Carrier{
+	{
+		Token:       "ups",
+		DisplayName: "UPS",
+		Aliases:     []string{"United Parcel Service", "UPS Mail Innovations"},
+		ServiceLevels: []string{
+			"ups_next_day_air_early_am",
+			"ups_next_day_air",
+			"ups_next_day_air_saver",
+			"ups_second_day_air_am",
+			"ups_second_day_air",
+			"ups_3_day_select",
+			"ups_ground",
+			"ups_surepost",
+		},
+	},
+	{
+		Token:       "usps",
+		DisplayName: "USPS",
+		Aliases:     []string{"United States Postal Service", "US Postal Service", "Postal Service"},
+		ServiceLevels: []string{
+			"usps_priority_express",
+			"usps_priority",
+			"usps_first",
+			"usps_ground_advantage",
+			"usps_parcel_select",
+			"usps_media_mail",
+		},
+	},
+	{
+		Token:       "fedex",
+		DisplayName: "FedEx",
+		Aliases:     []string{"Federal Express", "FedEx Express", "FedEx Ground"},
+		ServiceLevels: []string{
+			"fedex_first_overnight",
+			"fedex_priority_overnight",
+			"fedex_standard_overnight",
+			"fedex_2_day_am",
+			"fedex_2_day",
+			"fedex_express_saver",
+			"fedex_ground",
+			"fedex_home_delivery",
+			"fedex_smart_post",
+		},
+	},
+	{
+		Token:       "dhl_express",
+		DisplayName: "DHL Express",
+		Aliases:     []string{"DHL"},
+		ServiceLevels: []string{
+			"dhl_express_worldwide",
+			"dhl_express_domestic_express",
+		},
+	},
+	{
+		Token:       "ontrac",
+		DisplayName: "OnTrac",
+		ServiceLevels: []string{
+			"ontrac_sunrise",
+			"ontrac_ground",
+		},
+	},
+	{
+		Token:       "lasership",
+		DisplayName: "LaserShip",
+		ServiceLevels: []string{
+			"lasership_routed_delivery",
+		},
+	},
+}
+
+//carrierLookup maps every token, display name and alias, reduced to lower case letters and digits, to its carrier
+var carrierLookup = buildCarrierLookup()
+
+func buildCarrierLookup() map[string]*Carrier {
+	lookup := map[string]*Carrier{}
+	for _, carrier := range carrierRegistry {
+		lookup[carrierKey(carrier.Token)] = carrier
+		lookup[carrierKey(carrier.DisplayName)] = carrier
+		for _, alias := range carrier.Aliases {
+			lookup[carrierKey(alias)] = carrier
+		}
+	}
+	return lookup
+}
+
+//carrierKey ignores case, spacing and punctuation so "U.P.S." and "ups" match
+func carrierKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+//Carriers returns every registered carrier
+func Carriers() []*Carrier {
+	return carrierRegistry
+}
+
+//LookupCarrier finds a carrier by token, display name or alias
+func LookupCarrier(name string) (*Carrier, bool) {
+	carrier, ok := carrierLookup[carrierKey(name)]
+	return carrier, ok
+}
+
+//NormalizeCarrier returns the canonical token for a carrier name, or an error wrapping ErrUnknownCarrier
+func NormalizeCarrier(name string) (string, error) {
+	carrier, ok := LookupCarrier(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCarrier, name)
+	}
+	return carrier.Token, nil
+}
+
+//SupportsServiceLevel reports whether the carrier offers the service level token
+func (carrier Carrier) SupportsServiceLevel(token string) bool {
+	for _, serviceLevel := range carrier.ServiceLevels {
+		if strings.EqualFold(serviceLevel, token) {
+			return true
+		}
+	}
+	return false
+}
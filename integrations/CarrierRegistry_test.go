@@ -0,0 +1,50 @@
+package integrations_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+)
+
+func TestNormalizeCarrier(t *testing.T) {
+	cases := map[string]string{
+		"ups":                   "ups",
+		"UPS":                   "ups",
+		"U.P.S.":                "ups",
+		"United Parcel Service": "ups",
+		"united_parcel_service": "ups",
+		"USPS":                  "usps",
+		"US Postal Service":     "usps",
+		"FedEx":                 "fedex",
+		"Federal Express":       "fedex",
+		"dhl_express":           "dhl_express",
+		"DHL Express":           "dhl_express",
+	}
+
+	for name, expected := range cases {
+		token, err := integrations.NormalizeCarrier(name)
+		if err != nil {
+			t.Errorf("%q: %v", name, err)
+		} else if token != expected {
+			t.Errorf("%q: expected %s, got %s", name, expected, token)
+		}
+	}
+
+	if _, err := integrations.NormalizeCarrier("Pony Express"); !errors.Is(err, integrations.ErrUnknownCarrier) {
+		t.Errorf("expected ErrUnknownCarrier, got %v", err)
+	}
+}
+
+func TestCarrierServiceLevels(t *testing.T) {
+	carrier, ok := integrations.LookupCarrier("fedex")
+	if !ok {
+		t.Fatal("fedex is not registered")
+	}
+	if carrier.DisplayName != "FedEx" {
+		t.Errorf("unexpected display name %q", carrier.DisplayName)
+	}
+	if !carrier.SupportsServiceLevel("fedex_ground") || carrier.SupportsServiceLevel("ups_ground") {
+		t.Error("unexpected service level support")
+	}
+}
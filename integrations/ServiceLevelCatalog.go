@@ -0,0 +1,140 @@
+package integrations
+
+import (
+	"strings"
+)
+
+//SpeedClass groups service levels of different carriers that promise similar delivery speeds
+type SpeedClass string
+
+const (
+	SpeedClassOvernight SpeedClass = "overnight"
+	SpeedClassTwoDay    SpeedClass = "2_day"
+	SpeedClassThreeDay  SpeedClass = "3_day"
+	SpeedClassGround    SpeedClass = "ground"
+	SpeedClassEconomy   SpeedClass = "economy"
+	SpeedClassUnknown   SpeedClass = ""
+)
+
+//SpeedClasses lists every known speed class from fastest to slowest
+var SpeedClasses = []SpeedClass{
+	SpeedClassOvernight,
+	SpeedClassTwoDay,
+	SpeedClassThreeDay,
+	SpeedClassGround,
+	SpeedClassEconomy,
+}
+
+//ParseSpeedClass returns the speed class with the given name
+func ParseSpeedClass(name string) (SpeedClass, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, class := range SpeedClasses {
+		if string(class) == name {
+			return class, true
+		}
+	}
+	return SpeedClassUnknown, false
+}
+
+//ServiceLevelInfo is the normalized description of a carrier's service level
+type ServiceLevelInfo struct {
+	Token          string
+	Carrier        string
+	SpeedClass     SpeedClass
+	MinTransitDays int //business days
+	MaxTransitDays int //business days
+}
+
+var serviceLevelCatalog = map[string]ServiceLevelInfo{
+	"ups_next_day_air_early_am": {Carrier: "ups", SpeedClass: SpeedClassOvernight, MinTransitDays: 1, MaxTransitDays: 1},
+	"ups_next_day_air":          {Carrier: "ups", SpeedClass: SpeedClassOvernight, MinTransitDays: 1, MaxTransitDays: 1},
+	"ups_next_day_air_saver":    {Carrier: "ups", SpeedClass: SpeedClassOvernight, MinTransitDays: 1, MaxTransitDays: 1},
+	"ups_second_day_air_am":     {Carrier: "ups", SpeedClass: SpeedClassTwoDay, MinTransitDays: 2, MaxTransitDays: 2},
+	"ups_second_day_air":        {Carrier: "ups", SpeedClass: SpeedClassTwoDay, MinTransitDays: 2, MaxTransitDays: 2},
+	"ups_3_day_select":          {Carrier: "ups", SpeedClass: SpeedClassThreeDay, MinTransitDays: 3, MaxTransitDays: 3},
+	"ups_ground":                {Carrier: "ups", SpeedClass: SpeedClassGround, MinTransitDays: 1, MaxTransitDays: 5},
+	"ups_surepost":              {Carrier: "ups", SpeedClass: SpeedClassEconomy, MinTransitDays: 2, MaxTransitDays: 7},
+
+	"usps_priority_express": {Carrier: "usps", SpeedClass: SpeedClassOvernight, MinTransitDays: 1, MaxTransitDays: 2},
+	"usps_priority":         {Carrier: "usps", SpeedClass: SpeedClassTwoDay, MinTransitDays: 1, MaxTransitDays: 3},
+	"usps_first":            {Carrier: "usps", SpeedClass: SpeedClassGround, MinTransitDays: 1, MaxTransitDays: 5},
+	"usps_ground_advantage": {Carrier: "usps", SpeedClass: SpeedClassGround, MinTransitDays: 2, MaxTransitDays: 5},
+	"usps_parcel_select":    {Carrier: "usps", SpeedClass: SpeedClassEconomy, MinTransitDays: 2, MaxTransitDays: 8},
+	"usps_media_mail":       {Carrier: "usps", SpeedClass: SpeedClassEconomy, MinTransitDays: 2, MaxTransitDays: 8},
+
+	"fedex_first_overnight":    {Carrier: "fedex", SpeedClass: SpeedClassOvernight, MinTransitDays: 1, MaxTransitDays: 1},
+	"fedex_priority_overnight": {Carrier: "fedex", SpeedClass: SpeedClassOvernight, MinTransitDays: 1, MaxTransitDays: 1},
+	"fedex_standard_overnight": {Carrier: "fedex", SpeedClass: SpeedClassOvernight, MinTransitDays: 1, MaxTransitDays: 1},
+	"fedex_2_day_am":           {Carrier: "fedex", SpeedClass: SpeedClassTwoDay, MinTransitDays: 2, MaxTransitDays: 2},
+	"fedex_2_day":              {Carrier: "fedex", SpeedClass: SpeedClassTwoDay, MinTransitDays: 2, MaxTransitDays: 2},
+	"fedex_express_saver":      {Carrier: "fedex", SpeedClass: SpeedClassThreeDay, MinTransitDays: 3, MaxTransitDays: 3},
+	"fedex_ground":             {Carrier: "fedex", SpeedClass: SpeedClassGround, MinTransitDays: 1, MaxTransitDays: 5},
+	"fedex_home_delivery":      {Carrier: "fedex", SpeedClass: SpeedClassGround, MinTransitDays: 1, MaxTransitDays: 5},
+	"fedex_smart_post":         {Carrier: "fedex", SpeedClass: SpeedClassEconomy, MinTransitDays: 2, MaxTransitDays: 7},
+
+	"dhl_express_worldwide":        {Carrier: "dhl_express", SpeedClass: SpeedClassTwoDay, MinTransitDays: 2, MaxTransitDays: 5},
+	"dhl_express_domestic_express": {Carrier: "dhl_express", SpeedClass: SpeedClassOvernight, MinTransitDays: 1, MaxTransitDays: 1},
+
+	"ontrac_sunrise": {Carrier: "ontrac", SpeedClass: SpeedClassOvernight, MinTransitDays: 1, MaxTransitDays: 1},
+	"ontrac_ground":  {Carrier: "ontrac", SpeedClass: SpeedClassGround, MinTransitDays: 1, MaxTransitDays: 5},
+
+	"lasership_routed_delivery": {Carrier: "lasership", SpeedClass: SpeedClassGround, MinTransitDays: 1, MaxTransitDays: 5},
+}
+
+//serviceLevelKeywords classify service levels missing from the catalog by their name, checked in order
+var serviceLevelKeywords = []struct {
+	keywords []string
+	info     ServiceLevelInfo
+}{
+	{[]string{"overnight", "next day", "next_day", "express mail", "priority express", "priority mail express", "priority_mail_express", "sunrise"}, ServiceLevelInfo{SpeedClass: SpeedClassOvernight, MinTransitDays: 1, MaxTransitDays: 1}},
+	{[]string{"2 day", "2_day", "2nd day", "second day", "second_day", "priority"}, ServiceLevelInfo{SpeedClass: SpeedClassTwoDay, MinTransitDays: 2, MaxTransitDays: 2}},
+	{[]string{"3 day", "3_day", "express saver"}, ServiceLevelInfo{SpeedClass: SpeedClassThreeDay, MinTransitDays: 3, MaxTransitDays: 3}},
+	{[]string{"surepost", "smart post", "smart_post", "smartpost", "parcel select", "media mail", "economy", "saver"}, ServiceLevelInfo{SpeedClass: SpeedClassEconomy, MinTransitDays: 2, MaxTransitDays: 8}},
+	{[]string{"ground", "home delivery", "first class", "first-class", "standard"}, ServiceLevelInfo{SpeedClass: SpeedClassGround, MinTransitDays: 1, MaxTransitDays: 5}},
+}
+
+//LookupServiceLevel returns the catalog entry for a service level token
+func LookupServiceLevel(token string) (ServiceLevelInfo, bool) {
+	token = strings.ToLower(strings.TrimSpace(token))
+	info, ok := serviceLevelCatalog[token]
+	info.Token = token
+	return info, ok
+}
+
+//ClassifyServiceLevel normalizes an upstream service level, preferring the catalog entry for its token and falling back to keywords in its token or name.
+//The second return value is false when the service level could not be classified.
+func ClassifyServiceLevel(carrier string, serviceLevel *ServiceLevel) (ServiceLevelInfo, bool) {
+	if serviceLevel == nil {
+		return ServiceLevelInfo{Carrier: carrier}, false
+	}
+
+	if serviceLevel.Token != nil {
+		if info, ok := LookupServiceLevel(*serviceLevel.Token); ok {
+			return info, true
+		}
+	}
+
+	var text []string
+	if serviceLevel.Token != nil {
+		text = append(text, strings.ToLower(*serviceLevel.Token))
+	}
+	if serviceLevel.Name != nil {
+		text = append(text, strings.ToLower(*serviceLevel.Name))
+	}
+	joined := strings.Join(text, " ")
+
+	for _, candidate := range serviceLevelKeywords {
+		for _, keyword := range candidate.keywords {
+			if strings.Contains(joined, keyword) {
+				info := candidate.info
+				info.Carrier = carrier
+				if serviceLevel.Token != nil {
+					info.Token = *serviceLevel.Token
+				}
+				return info, true
+			}
+		}
+	}
+
+	return ServiceLevelInfo{Carrier: carrier}, false
+}
@@ -0,0 +1,49 @@
+package integrations_test
+
+import (
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+)
+
+func TestCatalogCoversRegistry(t *testing.T) {
+	for _, carrier := range integrations.Carriers() {
+		for _, token := range carrier.ServiceLevels {
+			info, ok := integrations.LookupServiceLevel(token)
+			if !ok {
+				t.Errorf("%s service level %s is missing from the catalog", carrier.Token, token)
+			} else if info.Carrier != carrier.Token {
+				t.Errorf("%s is catalogued under %s instead of %s", token, info.Carrier, carrier.Token)
+			}
+		}
+	}
+}
+
+func TestClassifyServiceLevel(t *testing.T) {
+	stringPtr := func(s string) *string { return &s }
+
+	cases := []struct {
+		serviceLevel *integrations.ServiceLevel
+		class        integrations.SpeedClass
+	}{
+		{&integrations.ServiceLevel{Token: stringPtr("ups_ground")}, integrations.SpeedClassGround},
+		{&integrations.ServiceLevel{Token: stringPtr("USPS_PRIORITY")}, integrations.SpeedClassTwoDay},
+		{&integrations.ServiceLevel{Name: stringPtr("FedEx Priority Overnight")}, integrations.SpeedClassOvernight},
+		{&integrations.ServiceLevel{Name: stringPtr("UPS 3 Day Select")}, integrations.SpeedClassThreeDay},
+		{&integrations.ServiceLevel{Name: stringPtr("Parcel Select Ground")}, integrations.SpeedClassEconomy},
+		{&integrations.ServiceLevel{Name: stringPtr("Priority Mail Express")}, integrations.SpeedClassOvernight},
+		{&integrations.ServiceLevel{Token: stringPtr("usps_priority_mail_express_flat_rate")}, integrations.SpeedClassOvernight},
+		{&integrations.ServiceLevel{Name: stringPtr("Priority Mail")}, integrations.SpeedClassTwoDay},
+	}
+
+	for _, c := range cases {
+		info, ok := integrations.ClassifyServiceLevel("any", c.serviceLevel)
+		if !ok || info.SpeedClass != c.class {
+			t.Errorf("%+v: expected %q, got %q", c.serviceLevel, c.class, info.SpeedClass)
+		}
+	}
+
+	if _, ok := integrations.ClassifyServiceLevel("ups", nil); ok {
+		t.Error("expected nil service level to be unclassified")
+	}
+}
@@ -0,0 +1,163 @@
+package integrations
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//ErrInvalidTrackingNumber is wrapped by every validation failure so callers can tell bad input from upstream errors
+var ErrInvalidTrackingNumber = errors.New("Invalid tracking number")
+
+type trackingNumberFormat struct {
+	carrier    string
+	name       string
+	pattern    *regexp.Regexp
+	checkDigit func(code string) bool //nil when the format has no check digit
+}
+
+//trackingNumberFormats are tried in order during carrier detection, so formats with check digits come before looser ones of the same length
+var trackingNumberFormats = []trackingNumberFormat{
+	{carrier: "ups", name: "UPS 1Z", pattern: regexp.MustCompile(`^1Z[0-9A-Z]{16}$`), checkDigit: upsCheckDigit},
+	{carrier: "usps", name: "USPS S10", pattern: regexp.MustCompile(`^[A-Z]{2}[0-9]{9}US$`), checkDigit: s10CheckDigit},
+	{carrier: "usps", name: "USPS IMpb", pattern: regexp.MustCompile(`^[0-9]{20,26}$`), checkDigit: mod10CheckDigit},
+	{carrier: "fedex", name: "FedEx Express", pattern: regexp.MustCompile(`^[0-9]{12}$`), checkDigit: fedexExpressCheckDigit},
+	{carrier: "fedex", name: "FedEx Ground", pattern: regexp.MustCompile(`^[0-9]{15}$`), checkDigit: mod10CheckDigit},
+	//every 20 digit code also matches USPS IMpb and fails detection there if its check digit does not match, so these need the carrier given
+	{carrier: "fedex", name: "FedEx Ground 20 digit", pattern: regexp.MustCompile(`^[0-9]{20}$`)},
+}
+
+//NormalizeTrackingNumber upper cases the code and strips the spaces and dashes people copy from labels
+func NormalizeTrackingNumber(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '\t' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+//ValidateTrackingNumber checks the normalized code against the carrier's known formats and check digits.
+//Carriers without known formats are not validated.
+func ValidateTrackingNumber(carrier string, code string) error {
+	if len(code) == 0 {
+		return fmt.Errorf("%w: tracking number is empty", ErrInvalidTrackingNumber)
+	}
+
+	carrier = strings.ToLower(carrier)
+
+	knownCarrier := false
+	formatMatched := false
+	for _, format := range trackingNumberFormats {
+		if format.carrier != carrier {
+			continue
+		}
+		knownCarrier = true
+
+		if !format.pattern.MatchString(code) {
+			continue
+		}
+		formatMatched = true
+
+		if format.checkDigit == nil || format.checkDigit(code) {
+			return nil
+		}
+	}
+
+	if !knownCarrier {
+		return nil
+	}
+	if formatMatched {
+		return fmt.Errorf("%w: check digit does not match for %s", ErrInvalidTrackingNumber, carrier)
+	}
+	return fmt.Errorf("%w: not a recognized %s tracking number format", ErrInvalidTrackingNumber, carrier)
+}
+
+//DetectCarrier infers the carrier from a normalized tracking number. A code failing the check digit of a format it matches is taken to be mistyped,
+//so looser formats without a check digit cannot claim it.
+func DetectCarrier(code string) (string, error) {
+	checkDigitFailed := false
+	for _, format := range trackingNumberFormats {
+		if !format.pattern.MatchString(code) {
+			continue
+		}
+		if format.checkDigit == nil {
+			if checkDigitFailed {
+				continue
+			}
+			return format.carrier, nil
+		}
+		if format.checkDigit(code) {
+			return format.carrier, nil
+		}
+		checkDigitFailed = true
+	}
+
+	if checkDigitFailed {
+		return "", fmt.Errorf("%w: check digit does not match", ErrInvalidTrackingNumber)
+	}
+	return "", fmt.Errorf("%w: unable to determine carrier", ErrInvalidTrackingNumber)
+}
+
+//upsCheckDigit validates 1Z numbers. Letters count as (ASCII - 3) mod 10, odd positions are weighted 1 and even positions 2.
+func upsCheckDigit(code string) bool {
+	body := code[2 : len(code)-1]
+
+	sum := 0
+	for i, r := range body {
+		value := int(r - '0')
+		if r >= 'A' && r <= 'Z' {
+			value = int(r-3) % 10
+		}
+		if i%2 == 1 {
+			value *= 2
+		}
+		sum += value
+	}
+
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
+
+//mod10CheckDigit is the GS1 style check used by USPS IMpb and FedEx Ground, weighting digits 3 and 1 alternately from the right
+func mod10CheckDigit(code string) bool {
+	sum := 0
+	weight := 3
+	for i := len(code) - 2; i >= 0; i-- {
+		sum += int(code[i]-'0') * weight
+		weight = 4 - weight
+	}
+
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
+
+//s10CheckDigit validates UPU S10 numbers such as LZ560920614US
+func s10CheckDigit(code string) bool {
+	weights := []int{8, 6, 4, 2, 3, 5, 9, 7}
+
+	sum := 0
+	for i, weight := range weights {
+		sum += int(code[2+i]-'0') * weight
+	}
+
+	check := 11 - sum%11
+	if check == 10 {
+		check = 0
+	} else if check == 11 {
+		check = 5
+	}
+
+	return check == int(code[10]-'0')
+}
+
+//fedexExpressCheckDigit validates 12 digit FedEx Express numbers, weighting digits 1, 3 and 7 from the right
+func fedexExpressCheckDigit(code string) bool {
+	weights := []int{1, 3, 7}
+
+	sum := 0
+	for i := 0; i < 11; i++ {
+		sum += int(code[10-i]-'0') * weights[i%3]
+	}
+
+	return sum%11%10 == int(code[11]-'0')
+}
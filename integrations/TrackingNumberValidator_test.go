@@ -0,0 +1,99 @@
+package integrations_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/integrations"
+)
+
+var validTrackingNumbers = map[string][]string{
+	"ups": {
+		"1Z8995V60312565703",
+		"1Z7939FF0325784213",
+		"1ZEW11070297619158",
+		"1ZX92R160301491530",
+		"1ZE8E8170316211954",
+	},
+	"usps": {
+		"9405511202555478899782",
+		"9374869903506066338485",
+		"92001901755477000454468833",
+		"LZ560920614US",
+		"9361269903506055837474",
+		"03071020000112345670",
+	},
+	"fedex": {
+		"781911664789",
+		"781903802700",
+		"782040078353",
+		"61290986580620629730",
+	},
+}
+
+func TestValidateTrackingNumber(t *testing.T) {
+	for carrier, codes := range validTrackingNumbers {
+		for _, code := range codes {
+			if err := integrations.ValidateTrackingNumber(carrier, code); err != nil {
+				t.Errorf("%s %s: %v", carrier, code, err)
+			}
+		}
+	}
+
+	invalid := map[string]string{
+		"ups":   "1Z8995V60312565704",     //check digit
+		"usps":  "9405511202555478899783", //check digit
+		"fedex": "781911664788",           //check digit
+	}
+	for carrier, code := range invalid {
+		err := integrations.ValidateTrackingNumber(carrier, code)
+		if !errors.Is(err, integrations.ErrInvalidTrackingNumber) {
+			t.Errorf("%s %s: expected ErrInvalidTrackingNumber, got %v", carrier, code, err)
+		}
+	}
+
+	if err := integrations.ValidateTrackingNumber("ups", "781911664789"); err == nil {
+		t.Error("expected a FedEx number to be rejected for UPS")
+	}
+}
+
+//ambiguousTrackingNumbers are valid for their carrier but cannot be told apart from a mistyped number of another, so are not detected
+var ambiguousTrackingNumbers = map[string]bool{
+	"61290986580620629730": true, //FedEx 20 digit, no check digit, fails USPS IMpb's
+}
+
+func TestDetectCarrier(t *testing.T) {
+	for carrier, codes := range validTrackingNumbers {
+		for _, code := range codes {
+			if ambiguousTrackingNumbers[code] {
+				continue
+			}
+			detected, err := integrations.DetectCarrier(code)
+			if err != nil {
+				t.Errorf("%s: %v", code, err)
+			} else if detected != carrier {
+				t.Errorf("%s: expected %s, got %s", code, carrier, detected)
+			}
+		}
+	}
+
+	if _, err := integrations.DetectCarrier("NOTATRACKINGNUMBER"); err == nil {
+		t.Error("expected detection to fail")
+	}
+
+	//a mistyped 20 digit USPS number is rejected rather than taken for FedEx
+	for code := range ambiguousTrackingNumbers {
+		if detected, err := integrations.DetectCarrier(code); !errors.Is(err, integrations.ErrInvalidTrackingNumber) {
+			t.Errorf("%s: expected ErrInvalidTrackingNumber, got %q %v", code, detected, err)
+		}
+	}
+	if detected, err := integrations.DetectCarrier("03071020000112345679"); !errors.Is(err, integrations.ErrInvalidTrackingNumber) {
+		t.Errorf("expected a mistyped USPS number to be rejected, got %q %v", detected, err)
+	}
+}
+
+func TestNormalizeTrackingNumber(t *testing.T) {
+	if code := integrations.NormalizeTrackingNumber(" 1z 8995-v603 1256 5703 "); code != "1Z8995V60312565703" {
+		t.Errorf("unexpected normalized code %q", code)
+	}
+}
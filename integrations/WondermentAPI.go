@@ -1,17 +1,39 @@
 package integrations
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/metrics"
+	"github.com/elorusso/wonderment-tech-eval/tracing"
 )
 
 const (
+	//WondermentBaseURL is the production Wonderment API host
+	WondermentBaseURL = "https://wrqnmf9e62.execute-api.us-east-1.amazonaws.com"
+
 	limitedTrackingServicePath = "Prod/limited_tracking_service"
 )
 
+var upstreamLatency = metrics.NewHistogram("upstream_request_duration_ms", "Wonderment tracking service latency per carrier and HTTP status", metrics.UnitMilliseconds, metrics.LatencyBuckets, "carrier", "status")
+
+//UpstreamStatusError is returned when Wonderment answers with a status other than 200 OK
+type UpstreamStatusError struct {
+	StatusCode int
+}
+
+func (err *UpstreamStatusError) Error() string {
+	return fmt.Sprintf("HTTP GET response was not OK (status %d)", err.StatusCode)
+}
+
 type WondermentAPI struct {
 	baseURL string
 }
@@ -22,7 +44,18 @@ func NewWondermentAPI(baseURL string) *WondermentAPI {
 	}
 }
 
-func (api WondermentAPI) LimitedTrackingSerice(carrier string, trackingCode string) (*WondermentShipment, error) {
+//LimitedTrackingSerice fetches the shipment and its tracking history, logging to the context's logger.
+//The request is traced as a client span and carries its traceparent header upstream.
+func (api WondermentAPI) LimitedTrackingSerice(ctx context.Context, carrier string, trackingCode string) (shipment *WondermentShipment, err error) {
+	log := logging.FromContext(ctx)
+
+	ctx, span := tracing.Start(ctx, "LimitedTrackingSerice", tracing.KindClient)
+	span.SetAttribute(logging.KeyCarrier, carrier)
+	defer func() {
+		span.RecordError(err)
+		span.Finish()
+	}()
+
 	//verify parameters
 	if len(carrier) == 0 {
 		return nil, errors.New("Invalid carrier")
@@ -45,13 +78,27 @@ func (api WondermentAPI) LimitedTrackingSerice(carrier string, trackingCode stri
 	requestURL.RawQuery = params.Encode()
 
 	//execute request
-	resp, err := http.Get(requestURL.String())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
+	tracing.Inject(ctx, req.Header)
+
+	startTime := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		upstreamLatency.ObserveSince(startTime, carrier, "error")
+		log.Error("upstream request failed", "duration_ms", time.Since(startTime), logging.KeyError, err)
+		return nil, err
+	}
+
+	upstreamLatency.ObserveSince(startTime, carrier, strconv.Itoa(resp.StatusCode))
+	span.SetAttribute("http.status_code", resp.StatusCode)
+	log.Info("upstream response", "status", resp.StatusCode, "duration_ms", time.Since(startTime))
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("HTTP GET response was not OK")
+		resp.Body.Close()
+		return nil, &UpstreamStatusError{StatusCode: resp.StatusCode}
 	}
 
 	//read and unmarshal body
@@ -61,7 +108,7 @@ func (api WondermentAPI) LimitedTrackingSerice(carrier string, trackingCode stri
 	}
 	defer resp.Body.Close()
 
-	shipment := &WondermentShipment{}
+	shipment = &WondermentShipment{}
 
 	err = json.Unmarshal(bodyData, shipment)
 	if err != nil {
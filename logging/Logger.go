@@ -0,0 +1,242 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Field keys shared across components so log lines can be searched consistently
+const (
+	KeyRequestID    = "request_id"
+	KeyTenantID     = "tenant_id"
+	KeyClientID     = "client_id"
+	KeyCarrier      = "carrier"
+	KeyTrackingCode = "tracking_code"
+	KeyShipmentID   = "shipment_id"
+	KeyError        = "error"
+)
+
+//Level orders log lines by severity
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (level Level) String() string {
+	if level < LevelDebug || level > LevelError {
+		return strconv.Itoa(int(level))
+	}
+	return levelNames[level]
+}
+
+//ParseLevel reads a level name, case insensitively
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("Unknown log level %q, expected debug, info, warn or error", name)
+}
+
+//redactedKeys are always logged as [REDACTED], whatever their value
+var redactedKeys = map[string]bool{
+	"api_key":       true,
+	"authorization": true,
+	"password":      true,
+	"secret":        true,
+	"token":         true,
+}
+
+//Logger writes one JSON object per line, carrying the fields attached with With.
+//Loggers are safe for concurrent use; children share their parent's writer.
+type Logger struct {
+	out        io.Writer
+	mu         *sync.Mutex
+	level      Level
+	redactArgs bool
+	fields     []interface{} //alternating keys and values
+}
+
+//New returns a logger writing lines at or above the level. When redactArgs is set, values wrapped with SQLArgs are logged as their types only.
+func New(out io.Writer, level Level, redactArgs bool) *Logger {
+	return &Logger{
+		out:        out,
+		mu:         &sync.Mutex{},
+		level:      level,
+		redactArgs: redactArgs,
+	}
+}
+
+var (
+	defaultLogger *Logger
+	defaultOnce   sync.Once
+)
+
+//Default returns the process logger, writing to stdout at LOG_LEVEL (info when unset).
+//SQL arguments are redacted unless LOG_REDACT_ARGS is false.
+func Default() *Logger {
+	defaultOnce.Do(func() {
+		level, err := ParseLevel(os.Getenv("LOG_LEVEL"))
+		if err != nil {
+			level = LevelInfo
+		}
+		redactArgs := true
+		if parsed, err := strconv.ParseBool(os.Getenv("LOG_REDACT_ARGS")); err == nil {
+			redactArgs = parsed
+		}
+		defaultLogger = New(os.Stdout, level, redactArgs)
+	})
+	return defaultLogger
+}
+
+//With returns a child logger that adds the key value pairs to every line
+func (logger *Logger) With(keyValues ...interface{}) *Logger {
+	child := *logger
+	child.fields = make([]interface{}, 0, len(logger.fields)+len(keyValues))
+	child.fields = append(child.fields, logger.fields...)
+	child.fields = append(child.fields, keyValues...)
+	return &child
+}
+
+//Enabled reports whether lines at the level are written
+func (logger *Logger) Enabled(level Level) bool {
+	return level >= logger.level
+}
+
+func (logger *Logger) Debug(msg string, keyValues ...interface{}) {
+	logger.log(LevelDebug, msg, keyValues)
+}
+
+func (logger *Logger) Info(msg string, keyValues ...interface{}) {
+	logger.log(LevelInfo, msg, keyValues)
+}
+
+func (logger *Logger) Warn(msg string, keyValues ...interface{}) {
+	logger.log(LevelWarn, msg, keyValues)
+}
+
+func (logger *Logger) Error(msg string, keyValues ...interface{}) {
+	logger.log(LevelError, msg, keyValues)
+}
+
+func (logger *Logger) log(level Level, msg string, keyValues []interface{}) {
+	if !logger.Enabled(level) {
+		return
+	}
+
+	var line bytes.Buffer
+	line.WriteString(`{"time":`)
+	writeJSON(&line, time.Now().UTC().Format(time.RFC3339Nano))
+	line.WriteString(`,"level":`)
+	writeJSON(&line, level.String())
+	line.WriteString(`,"msg":`)
+	writeJSON(&line, msg)
+	logger.writeFields(&line, logger.fields)
+	logger.writeFields(&line, keyValues)
+	line.WriteString("}\n")
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	logger.out.Write(line.Bytes())
+}
+
+func (logger *Logger) writeFields(line *bytes.Buffer, keyValues []interface{}) {
+	for i := 0; i < len(keyValues); i += 2 {
+		key := fmt.Sprint(keyValues[i])
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keyValues) {
+			value = keyValues[i+1]
+		}
+
+		line.WriteByte(',')
+		writeJSON(line, key)
+		line.WriteByte(':')
+		writeJSON(line, logger.fieldValue(key, value))
+	}
+}
+
+//fieldValue applies redaction and makes errors and stringers readable
+func (logger *Logger) fieldValue(key string, value interface{}) interface{} {
+	if redactedKeys[strings.ToLower(key)] {
+		return "[REDACTED]"
+	}
+
+	switch v := value.(type) {
+	case sqlArgs:
+		if !logger.redactArgs {
+			return []interface{}(v)
+		}
+		redacted := make([]string, len(v))
+		for i, arg := range v {
+			redacted[i] = fmt.Sprintf("[%T]", arg)
+		}
+		return redacted
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.Milliseconds()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return value
+}
+
+func writeJSON(line *bytes.Buffer, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	line.Write(data)
+}
+
+type sqlArgs []interface{}
+
+//SQLArgs marks query arguments, which may hold customer data, for redaction
+func SQLArgs(args []interface{}) interface{} {
+	return sqlArgs(args)
+}
+
+type contextKey struct{}
+
+//WithLogger attaches the logger to the context
+func WithLogger(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+type requestIDContextKey struct{}
+
+//WithRequestID attaches the API Gateway request ID to the context, for records that outlive the log lines such as audit entries
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+//RequestIDFromContext returns the request ID attached to the context, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+//FromContext returns the logger attached to the context, or the default logger
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*Logger); ok {
+			return logger
+		}
+	}
+	return Default()
+}
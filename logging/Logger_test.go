@@ -0,0 +1,98 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/logging"
+)
+
+func decodeLines(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, raw := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if len(raw) == 0 {
+			continue
+		}
+		line := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			t.Fatalf("line is not JSON: %s", raw)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestLoggerFieldsAndLevels(t *testing.T) {
+	var out bytes.Buffer
+	logger := logging.New(&out, logging.LevelInfo, true).With(logging.KeyRequestID, "req-1")
+
+	logger.Debug("hidden")
+	logger.With(logging.KeyCarrier, "ups").Info("fetched", "duration", 1500*time.Millisecond, logging.KeyError, errors.New("boom"))
+	logger.Error("failed", "api_key", "wm_secret")
+
+	lines := decodeLines(t, &out)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %s", len(lines), out.String())
+	}
+
+	first := lines[0]
+	if first["level"] != "info" || first["msg"] != "fetched" || first["request_id"] != "req-1" || first["carrier"] != "ups" {
+		t.Errorf("unexpected line %v", first)
+	}
+	if first["duration"] != float64(1500) || first["error"] != "boom" {
+		t.Errorf("unexpected values %v", first)
+	}
+	if _, ok := first["time"]; !ok {
+		t.Errorf("missing time %v", first)
+	}
+
+	second := lines[1]
+	if second["api_key"] != "[REDACTED]" || second["carrier"] != nil {
+		t.Errorf("unexpected line %v", second)
+	}
+}
+
+func TestLoggerSQLArgs(t *testing.T) {
+	args := []interface{}{"1Z999", 42}
+
+	var out bytes.Buffer
+	logging.New(&out, logging.LevelDebug, true).Debug("query", "args", logging.SQLArgs(args))
+	logging.New(&out, logging.LevelDebug, false).Debug("query", "args", logging.SQLArgs(args))
+
+	lines := decodeLines(t, &out)
+	redacted := lines[0]["args"].([]interface{})
+	if redacted[0] != "[string]" || redacted[1] != "[int]" {
+		t.Errorf("expected redacted args, got %v", redacted)
+	}
+	if !strings.Contains(out.String(), "1Z999") {
+		t.Errorf("expected unredacted args when redaction is off: %s", out.String())
+	}
+	if strings.Count(out.String(), "1Z999") != 1 {
+		t.Errorf("expected args only once: %s", out.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := logging.ParseLevel("WARN")
+	if err != nil || level != logging.LevelWarn {
+		t.Errorf("expected warn, got %v %v", level, err)
+	}
+	if _, err := logging.ParseLevel("verbose"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	logger := logging.New(&bytes.Buffer{}, logging.LevelInfo, true)
+	if logging.FromContext(logging.WithLogger(context.Background(), logger)) != logger {
+		t.Error("expected the attached logger")
+	}
+	if logging.FromContext(context.Background()) != logging.Default() {
+		t.Error("expected the default logger")
+	}
+}
@@ -0,0 +1,339 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Unit is the CloudWatch unit a metric is reported in
+type Unit string
+
+const (
+	UnitMilliseconds Unit = "Milliseconds"
+	UnitCount        Unit = "Count"
+)
+
+//LatencyBuckets are histogram upper bounds in milliseconds for request and query latencies
+var LatencyBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+//CountBuckets are histogram upper bounds for per operation counts, e.g. events per ingest
+var CountBuckets = []float64{0, 1, 5, 10, 25, 50, 100}
+
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindHistogram kind = "histogram"
+)
+
+//Registry aggregates metrics for Prometheus scraping and, when EMF is enabled, buffers raw observations until the next FlushEMF
+type Registry struct {
+	mu        sync.Mutex
+	metrics   []*metric
+	emf       bool
+	namespace string
+}
+
+type metric struct {
+	name    string
+	help    string
+	kind    kind
+	unit    Unit
+	labels  []string
+	buckets []float64
+	series  map[string]*series
+}
+
+type series struct {
+	labelValues  []string
+	count        uint64
+	sum          float64
+	bucketCounts []uint64
+	pending      []float64 //observations since the last EMF flush
+}
+
+//NewRegistry returns an empty registry. EMF lines are only buffered after EnableEMF.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+//EnableEMF buffers observations so FlushEMF can write them as CloudWatch Embedded Metric Format lines in the namespace
+func (r *Registry) EnableEMF(namespace string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emf = true
+	r.namespace = namespace
+}
+
+//EMFEnabled reports whether observations are buffered for FlushEMF
+func (r *Registry) EMFEnabled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.emf
+}
+
+func (r *Registry) register(m *metric) *metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.metrics {
+		if existing.name == m.name {
+			panic(fmt.Sprintf("metrics: %s registered twice", m.name))
+		}
+	}
+	m.series = map[string]*series{}
+	r.metrics = append(r.metrics, m)
+	return m
+}
+
+//NewCounter registers a counter whose series are identified by the label values
+func (r *Registry) NewCounter(name string, help string, labels ...string) *Counter {
+	return &Counter{registry: r, metric: r.register(&metric{name: name, help: help, kind: kindCounter, unit: UnitCount, labels: labels})}
+}
+
+//NewHistogram registers a histogram with the given bucket upper bounds
+func (r *Registry) NewHistogram(name string, help string, unit Unit, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{registry: r, metric: r.register(&metric{name: name, help: help, kind: kindHistogram, unit: unit, labels: labels, buckets: buckets})}
+}
+
+//record adds an observation to the series for the label values, missing values are recorded as empty
+func (r *Registry) record(m *metric, value float64, labelValues []string) {
+	values := make([]string, len(m.labels))
+	copy(values, labelValues)
+	key := strings.Join(values, "\xff")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := m.series[key]
+	if s == nil {
+		s = &series{labelValues: values, bucketCounts: make([]uint64, len(m.buckets))}
+		m.series[key] = s
+	}
+
+	s.count++
+	s.sum += value
+	for i, bound := range m.buckets {
+		if value <= bound {
+			s.bucketCounts[i]++
+		}
+	}
+	if r.emf {
+		s.pending = append(s.pending, value)
+	}
+}
+
+//Counter is a monotonically increasing count
+type Counter struct {
+	registry *Registry
+	metric   *metric
+}
+
+//Inc adds one to the series for the label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+//Add adds delta to the series for the label values
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	c.registry.record(c.metric, delta, labelValues)
+}
+
+//Histogram tracks the distribution of observed values
+type Histogram struct {
+	registry *Registry
+	metric   *metric
+}
+
+//Observe records a value in the series for the label values
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.registry.record(h.metric, value, labelValues)
+}
+
+//ObserveSince records the milliseconds elapsed since start
+func (h *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(float64(time.Since(start))/float64(time.Millisecond), labelValues...)
+}
+
+//WritePrometheus writes every metric in the Prometheus text exposition format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out strings.Builder
+	for _, m := range r.metrics {
+		fmt.Fprintf(&out, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(&out, "# TYPE %s %s\n", m.name, m.kind)
+
+		for _, s := range m.sortedSeries() {
+			labels := m.formatLabels(s.labelValues)
+			if m.kind == kindCounter {
+				fmt.Fprintf(&out, "%s%s %s\n", m.name, braces(labels), formatFloat(s.sum))
+				continue
+			}
+
+			for i, bound := range m.buckets {
+				fmt.Fprintf(&out, "%s_bucket%s %d\n", m.name, braces(appendLabel(labels, "le", formatFloat(bound))), s.bucketCounts[i])
+			}
+			fmt.Fprintf(&out, "%s_bucket%s %d\n", m.name, braces(appendLabel(labels, "le", "+Inf")), s.count)
+			fmt.Fprintf(&out, "%s_sum%s %s\n", m.name, braces(labels), formatFloat(s.sum))
+			fmt.Fprintf(&out, "%s_count%s %d\n", m.name, braces(labels), s.count)
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+//Handler serves WritePrometheus, for mounting at /metrics
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("content-type", "text/plain; version=0.0.4")
+		r.WritePrometheus(w)
+	})
+}
+
+//FlushEMF writes one CloudWatch Embedded Metric Format line per series observed since the last flush, with the labels as dimensions.
+//Lambda forwards stdout to CloudWatch Logs, which extracts the metrics. Does nothing unless EMF is enabled.
+func (r *Registry) FlushEMF(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.emf {
+		return nil
+	}
+
+	timestamp := time.Now().UnixNano() / int64(time.Millisecond)
+	for _, m := range r.metrics {
+		for _, s := range m.sortedSeries() {
+			if len(s.pending) == 0 {
+				continue
+			}
+
+			dimensions := m.labels
+			if dimensions == nil {
+				dimensions = []string{}
+			}
+			line := map[string]interface{}{
+				"_aws": map[string]interface{}{
+					"Timestamp": timestamp,
+					"CloudWatchMetrics": []interface{}{map[string]interface{}{
+						"Namespace":  r.namespace,
+						"Dimensions": [][]string{dimensions},
+						"Metrics":    []interface{}{map[string]string{"Name": m.name, "Unit": string(m.unit)}},
+					}},
+				},
+			}
+			for i, label := range m.labels {
+				line[label] = s.labelValues[i]
+			}
+			if m.kind == kindCounter {
+				total := 0.0
+				for _, value := range s.pending {
+					total += value
+				}
+				line[m.name] = total
+			} else {
+				line[m.name] = s.pending
+			}
+
+			data, err := json.Marshal(line)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(append(data, '\n')); err != nil {
+				return err
+			}
+			s.pending = nil
+		}
+	}
+
+	return nil
+}
+
+func (m *metric) sortedSeries() []*series {
+	keys := make([]string, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sorted := make([]*series, len(keys))
+	for i, key := range keys {
+		sorted[i] = m.series[key]
+	}
+	return sorted
+}
+
+func (m *metric) formatLabels(values []string) []string {
+	labels := make([]string, len(m.labels))
+	for i, label := range m.labels {
+		labels[i] = label + `="` + labelEscaper.Replace(values[i]) + `"`
+	}
+	return labels
+}
+
+func appendLabel(labels []string, name string, value string) []string {
+	return append(append([]string{}, labels...), name+`="`+labelEscaper.Replace(value)+`"`)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func braces(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+//DefaultRegistry is the process registry. EMF is enabled when running in Lambda or when METRICS_EMF is true,
+//in the METRICS_NAMESPACE namespace (default Wonderment).
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry()
+
+	enabled := len(os.Getenv("AWS_LAMBDA_FUNCTION_NAME")) != 0
+	if parsed, err := strconv.ParseBool(os.Getenv("METRICS_EMF")); err == nil {
+		enabled = parsed
+	}
+	if enabled {
+		namespace := os.Getenv("METRICS_NAMESPACE")
+		if len(namespace) == 0 {
+			namespace = "Wonderment"
+		}
+		registry.EnableEMF(namespace)
+	}
+
+	return registry
+}
+
+//NewCounter registers a counter on the default registry
+func NewCounter(name string, help string, labels ...string) *Counter {
+	return DefaultRegistry.NewCounter(name, help, labels...)
+}
+
+//NewHistogram registers a histogram on the default registry
+func NewHistogram(name string, help string, unit Unit, buckets []float64, labels ...string) *Histogram {
+	return DefaultRegistry.NewHistogram(name, help, unit, buckets, labels...)
+}
+
+//FlushEMF flushes the default registry to stdout, at the end of each Lambda invocation
+func FlushEMF() error {
+	return DefaultRegistry.FlushEMF(os.Stdout)
+}
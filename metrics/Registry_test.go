@@ -0,0 +1,107 @@
+package metrics_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/metrics"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	registry := metrics.NewRegistry()
+	outcomes := registry.NewCounter("ingest_total", "Shipment ingests by outcome", "carrier", "outcome")
+	latency := registry.NewHistogram("upstream_request_duration_ms", "Upstream latency", metrics.UnitMilliseconds, []float64{10, 100}, "carrier")
+
+	outcomes.Inc("ups", "success")
+	outcomes.Inc("ups", "success")
+	outcomes.Inc("fedex", `bad"value`)
+	latency.Observe(5, "ups")
+	latency.Observe(50, "ups")
+	latency.Observe(500, "ups")
+
+	var out bytes.Buffer
+	if err := registry.WritePrometheus(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"# TYPE ingest_total counter",
+		`ingest_total{carrier="ups",outcome="success"} 2`,
+		`ingest_total{carrier="fedex",outcome="bad\"value"} 1`,
+		"# TYPE upstream_request_duration_ms histogram",
+		`upstream_request_duration_ms_bucket{carrier="ups",le="10"} 1`,
+		`upstream_request_duration_ms_bucket{carrier="ups",le="100"} 2`,
+		`upstream_request_duration_ms_bucket{carrier="ups",le="+Inf"} 3`,
+		`upstream_request_duration_ms_sum{carrier="ups"} 555`,
+		`upstream_request_duration_ms_count{carrier="ups"} 3`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestFlushEMF(t *testing.T) {
+	registry := metrics.NewRegistry()
+	latency := registry.NewHistogram("db_query_duration_ms", "Query latency", metrics.UnitMilliseconds, metrics.LatencyBuckets, "manager", "method")
+	outcomes := registry.NewCounter("ingest_total", "Shipment ingests by outcome", "outcome")
+
+	//observations before EMF is enabled are only aggregated
+	latency.Observe(1, "shipments", "InsertShipment")
+
+	var out bytes.Buffer
+	if err := registry.FlushEMF(&out); err != nil || out.Len() != 0 {
+		t.Fatalf("expected no output with EMF disabled, got %q %v", out.String(), err)
+	}
+
+	registry.EnableEMF("Test")
+	latency.Observe(12, "shipments", "InsertShipment")
+	latency.Observe(30, "shipments", "InsertShipment")
+	outcomes.Inc("success")
+	outcomes.Inc("success")
+
+	if err := registry.FlushEMF(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d:\n%s", len(lines), out.String())
+	}
+
+	var query struct {
+		AWS struct {
+			CloudWatchMetrics []struct {
+				Namespace  string
+				Dimensions [][]string
+				Metrics    []struct{ Name, Unit string }
+			}
+		} `json:"_aws"`
+		Manager string    `json:"manager"`
+		Method  string    `json:"method"`
+		Values  []float64 `json:"db_query_duration_ms"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &query); err != nil {
+		t.Fatal(err)
+	}
+	directive := query.AWS.CloudWatchMetrics[0]
+	if directive.Namespace != "Test" || len(directive.Dimensions[0]) != 2 || directive.Metrics[0].Unit != "Milliseconds" {
+		t.Errorf("unexpected directive %+v", directive)
+	}
+	if query.Manager != "shipments" || query.Method != "InsertShipment" || len(query.Values) != 2 || query.Values[1] != 30 {
+		t.Errorf("unexpected line %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"ingest_total":2`) {
+		t.Errorf("expected summed counter, got %s", lines[1])
+	}
+
+	//flushed observations are not written again
+	out.Reset()
+	registry.FlushEMF(&out)
+	if out.Len() != 0 {
+		t.Errorf("expected nothing to flush, got %s", out.String())
+	}
+}
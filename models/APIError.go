@@ -0,0 +1,68 @@
+package models
+
+import (
+	"net/http"
+)
+
+//error codes are part of the API contract, clients branch on them rather than on messages
+const (
+	ErrorCodeInvalidRequest       = "invalid_request"
+	ErrorCodeInvalidJSON          = "invalid_json"
+	ErrorCodeValidationFailed     = "validation_failed"
+	ErrorCodeUnauthorized         = "unauthorized"
+	ErrorCodeForbidden            = "forbidden"
+	ErrorCodeNotFound             = "not_found"
+	ErrorCodeMethodNotAllowed     = "method_not_allowed"
+	ErrorCodeUnsupportedMediaType = "unsupported_media_type"
+	ErrorCodeUpstreamError        = "upstream_error"
+	ErrorCodeServiceUnavailable   = "service_unavailable"
+	ErrorCodeTimeout              = "timeout"
+	ErrorCodeInternal             = "internal_error"
+)
+
+//field error codes explain why a single parameter was rejected
+const (
+	FieldCodeRequired     = "required"
+	FieldCodeInvalid      = "invalid"
+	FieldCodeInvalidType  = "invalid_type"
+	FieldCodeOutOfRange   = "out_of_range"
+	FieldCodeUnknownValue = "unknown_value"
+)
+
+//FieldError describes why a single request parameter was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+//APIError is the body of every error response. Retryable tells clients whether repeating the same request may succeed.
+type APIError struct {
+	Status    int          `json:"-"`
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Retryable bool         `json:"retryable"`
+}
+
+func (err *APIError) Error() string {
+	return err.Message
+}
+
+//NewAPIError creates an error with the status and code, retryable for 429, 503 and 504 responses
+func NewAPIError(status int, code string, message string) *APIError {
+	return &APIError{
+		Status:    status,
+		Code:      code,
+		Message:   message,
+		Retryable: status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout,
+	}
+}
+
+//NewValidationError creates a 400 rejecting a single parameter, its message is repeated at the top level for clients that only read that
+func NewValidationError(field string, code string, message string) *APIError {
+	err := NewAPIError(http.StatusBadRequest, ErrorCodeValidationFailed, message)
+	err.Details = []FieldError{{Field: field, Code: code, Message: message}}
+	return err
+}
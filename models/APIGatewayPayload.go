@@ -1,5 +1,13 @@
 package models
 
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+)
+
+//APIGatewayPayload is the Lambda proxy event for both REST APIs (payload version 1.0) and HTTP APIs (payload version 2.0).
+//Fields that only exist in one version are left empty for the other; use the helper methods to read values regardless of version.
 type APIGatewayPayload struct {
 	Version               string
 	RouteKey              string
@@ -10,8 +18,16 @@ type APIGatewayPayload struct {
 	QueryStringParameters map[string]string
 	StageVariables        map[string]string
 	Body                  string
+	IsBase64Encoded       bool
 	PathParameters        map[string]string
 	RequestContext        *RequestContext
+
+	//REST API (v1) only
+	Resource                        string
+	Path                            string
+	HTTPMethod                      string
+	MultiValueHeaders               map[string][]string
+	MultiValueQueryStringParameters map[string][]string
 }
 
 type RequestContext struct {
@@ -24,6 +40,14 @@ type RequestContext struct {
 	RouteKey     string
 	Stage        string
 	TimeEpoch    int
+
+	//REST API (v1) only
+	HTTPMethod       string
+	Path             string
+	Protocol         string
+	ResourcePath     string
+	RequestTimeEpoch int
+	Identity         *Identity
 }
 
 type HTTPInfo struct {
@@ -33,3 +57,161 @@ type HTTPInfo struct {
 	SourceIP  string
 	UserAgent string
 }
+
+type Identity struct {
+	SourceIP  string
+	UserAgent string
+}
+
+//IsV1 reports whether the payload came from a REST API rather than an HTTP API
+func (p *APIGatewayPayload) IsV1() bool {
+	if len(p.Version) > 0 {
+		return strings.HasPrefix(p.Version, "1")
+	}
+	return len(p.HTTPMethod) > 0
+}
+
+//Method returns the HTTP method of the request
+func (p *APIGatewayPayload) Method() string {
+	if len(p.HTTPMethod) > 0 {
+		return p.HTTPMethod
+	}
+	if p.RequestContext != nil && p.RequestContext.HTTP != nil {
+		return p.RequestContext.HTTP.Method
+	}
+	return ""
+}
+
+//RequestPath returns the path of the request
+func (p *APIGatewayPayload) RequestPath() string {
+	if len(p.RawPath) > 0 {
+		return p.RawPath
+	}
+	return p.Path
+}
+
+//RequestID returns the API Gateway request ID
+func (p *APIGatewayPayload) RequestID() string {
+	if p.RequestContext == nil {
+		return ""
+	}
+	return p.RequestContext.RequestID
+}
+
+//SourceIP returns the IP address of the caller
+func (p *APIGatewayPayload) SourceIP() string {
+	if p.RequestContext == nil {
+		return ""
+	}
+	if p.RequestContext.HTTP != nil {
+		return p.RequestContext.HTTP.SourceIP
+	}
+	if p.RequestContext.Identity != nil {
+		return p.RequestContext.Identity.SourceIP
+	}
+	return ""
+}
+
+//listHeaders are headers whose value is a comma separated list, see RFC 7230 section 7. Other headers, such as Date or User-Agent, may contain commas within one value.
+var listHeaders = map[string]bool{
+	"accept":            true,
+	"accept-charset":    true,
+	"accept-encoding":   true,
+	"accept-language":   true,
+	"allow":             true,
+	"cache-control":     true,
+	"connection":        true,
+	"content-encoding":  true,
+	"forwarded":         true,
+	"if-match":          true,
+	"if-none-match":     true,
+	"pragma":            true,
+	"te":                true,
+	"trailer":           true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+	"vary":              true,
+	"via":               true,
+	"x-forwarded-for":   true,
+}
+
+//Header returns the value of the named header as received, matching the name case-insensitively. Repeated headers are joined with commas, as HTTP APIs send them.
+func (p *APIGatewayPayload) Header(name string) string {
+	for key, values := range p.MultiValueHeaders {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return strings.Join(values, ",")
+		}
+	}
+	for key, value := range p.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+//HeaderValues returns every value of the named header, matching the name case-insensitively.
+//HTTP APIs join repeated headers with commas, so values of list headers are split back apart. Other headers are returned whole.
+func (p *APIGatewayPayload) HeaderValues(name string) []string {
+	for key, values := range p.MultiValueHeaders {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values
+		}
+	}
+	for key, value := range p.Headers {
+		if strings.EqualFold(key, name) {
+			if p.IsV1() || !listHeaders[strings.ToLower(name)] {
+				return []string{value}
+			}
+			values := strings.Split(value, ",")
+			for i := range values {
+				values[i] = strings.TrimSpace(values[i])
+			}
+			return values
+		}
+	}
+	return nil
+}
+
+//QueryParam returns the first value of the named query string parameter
+func (p *APIGatewayPayload) QueryParam(name string) string {
+	values := p.QueryParamValues(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+//QueryParamValues returns every value of the named query string parameter
+func (p *APIGatewayPayload) QueryParamValues(name string) []string {
+	if values, ok := p.MultiValueQueryStringParameters[name]; ok {
+		return values
+	}
+
+	//HTTP APIs join repeated parameters with commas, the raw query string keeps them apart
+	if len(p.RawQueryString) > 0 {
+		if query, err := url.ParseQuery(p.RawQueryString); err == nil {
+			if values, ok := query[name]; ok {
+				return values
+			}
+		}
+	}
+
+	if value, ok := p.QueryStringParameters[name]; ok {
+		return []string{value}
+	}
+	return nil
+}
+
+//HasQueryParams reports whether the request carried any query string parameters
+func (p *APIGatewayPayload) HasQueryParams() bool {
+	return len(p.QueryStringParameters) > 0 || len(p.MultiValueQueryStringParameters) > 0 || len(p.RawQueryString) > 0
+}
+
+//DecodedBody returns the request body, decoding it if API Gateway delivered it base64 encoded
+func (p *APIGatewayPayload) DecodedBody() ([]byte, error) {
+	if !p.IsBase64Encoded {
+		return []byte(p.Body), nil
+	}
+	return base64.StdEncoding.DecodeString(p.Body)
+}
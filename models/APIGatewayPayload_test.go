@@ -0,0 +1,118 @@
+package models_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const restAPIEvent = `{
+	"resource": "/ingest-shipment",
+	"path": "/ingest-shipment",
+	"httpMethod": "POST",
+	"headers": {"Content-Type": "application/json", "X-Api-Key": "abc"},
+	"multiValueHeaders": {"Content-Type": ["application/json"], "X-Api-Key": ["abc"]},
+	"queryStringParameters": {"carrier": "fedex"},
+	"multiValueQueryStringParameters": {"carrier": ["ups", "fedex"]},
+	"requestContext": {
+		"requestId": "v1-request",
+		"httpMethod": "POST",
+		"identity": {"sourceIp": "10.0.0.1"}
+	},
+	"body": "eyJjYXJyaWVyIjoidXBzIn0=",
+	"isBase64Encoded": true
+}`
+
+const httpAPIEvent = `{
+	"version": "2.0",
+	"routeKey": "GET /average-time-in-transit",
+	"rawPath": "/average-time-in-transit",
+	"rawQueryString": "carrier=ups&carrier=fedex",
+	"headers": {"accept": "text/html, application/json", "x-api-key": "abc"},
+	"queryStringParameters": {"carrier": "ups,fedex"},
+	"requestContext": {
+		"requestId": "v2-request",
+		"http": {"method": "GET", "path": "/average-time-in-transit", "sourceIp": "10.0.0.2"}
+	},
+	"body": "{\"carrier\":\"ups\"}",
+	"isBase64Encoded": false
+}`
+
+func TestRESTAPIPayload(t *testing.T) {
+	payload := &models.APIGatewayPayload{}
+	if err := json.Unmarshal([]byte(restAPIEvent), payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if !payload.IsV1() {
+		t.Error("expected a v1 payload")
+	}
+	if payload.Method() != "POST" || payload.RequestPath() != "/ingest-shipment" {
+		t.Errorf("unexpected method or path: %s %s", payload.Method(), payload.RequestPath())
+	}
+	if payload.RequestID() != "v1-request" || payload.SourceIP() != "10.0.0.1" {
+		t.Errorf("unexpected request ID or source IP: %s %s", payload.RequestID(), payload.SourceIP())
+	}
+	if payload.Header("x-api-key") != "abc" {
+		t.Errorf("case-insensitive header lookup failed: %q", payload.Header("x-api-key"))
+	}
+	if values := payload.QueryParamValues("carrier"); !reflect.DeepEqual(values, []string{"ups", "fedex"}) {
+		t.Errorf("unexpected query values %v", values)
+	}
+
+	body, err := payload.DecodedBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"carrier":"ups"}` {
+		t.Errorf("unexpected body %q", body)
+	}
+}
+
+func TestHTTPAPIPayload(t *testing.T) {
+	payload := &models.APIGatewayPayload{}
+	if err := json.Unmarshal([]byte(httpAPIEvent), payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if payload.IsV1() {
+		t.Error("expected a v2 payload")
+	}
+	if payload.Method() != "GET" || payload.RequestPath() != "/average-time-in-transit" {
+		t.Errorf("unexpected method or path: %s %s", payload.Method(), payload.RequestPath())
+	}
+	if payload.RequestID() != "v2-request" || payload.SourceIP() != "10.0.0.2" {
+		t.Errorf("unexpected request ID or source IP: %s %s", payload.RequestID(), payload.SourceIP())
+	}
+	if values := payload.HeaderValues("Accept"); !reflect.DeepEqual(values, []string{"text/html", "application/json"}) {
+		t.Errorf("unexpected header values %v", values)
+	}
+
+	//commas only separate values of list headers
+	payload.Headers["date"] = "Mon, 19 Oct 2026 08:00:00 GMT"
+	if value := payload.Header("Date"); value != "Mon, 19 Oct 2026 08:00:00 GMT" {
+		t.Errorf("single valued header truncated: %q", value)
+	}
+	if values := payload.HeaderValues("Date"); !reflect.DeepEqual(values, []string{"Mon, 19 Oct 2026 08:00:00 GMT"}) {
+		t.Errorf("single valued header split: %v", values)
+	}
+	if value := payload.Header("Accept"); value != payload.Headers["accept"] {
+		t.Errorf("expected the raw joined value, got %q", value)
+	}
+	if payload.QueryParam("carrier") != "ups" {
+		t.Errorf("unexpected query value %q", payload.QueryParam("carrier"))
+	}
+	if payload.QueryParam("missing") != "" {
+		t.Error("expected empty value for missing parameter")
+	}
+
+	body, err := payload.DecodedBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"carrier":"ups"}` {
+		t.Errorf("unexpected body %q", body)
+	}
+}
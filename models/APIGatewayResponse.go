@@ -1,9 +1,11 @@
 package models
 
+//APIGatewayResponse is the Lambda proxy response. REST APIs (v1) reject cookies, so they are omitted when empty.
 type APIGatewayResponse struct {
-	Cookies         []string          `json:"cookies"`
-	IsBase64Encoded bool              `json:"isBase64Encoded"`
-	StatusCode      int               `json:"statusCode"`
-	Headers         map[string]string `json:"headers"`
-	Body            string            `json:"body"`
+	Cookies           []string            `json:"cookies,omitempty"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders,omitempty"`
+	Body              string              `json:"body"`
 }
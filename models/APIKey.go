@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+//APIKey is a stored API key. Only the hash of the key is kept, Prefix identifies the key to humans.
+type APIKey struct {
+	KeyID     string
+	ClientID  string
+	TenantID  string
+	Name      string
+	Prefix    string
+	Hash      string
+	Scopes    []string
+	CreatedAt time.Time
+	ExpiresAt *time.Time
+	RevokedAt *time.Time
+}
+
+//IsActive reports whether the key can be used at the given time
+func (key APIKey) IsActive(now time.Time) bool {
+	if key.RevokedAt != nil && !key.RevokedAt.After(now) {
+		return false
+	}
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return false
+	}
+	return true
+}
+
+//HasScope reports whether the key grants the scope. The "*" scope grants everything.
+func (key APIKey) HasScope(scope string) bool {
+	for _, granted := range key.Scopes {
+		if granted == scope || granted == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	AlertMetricMedianTransit = "median_transit_hours"
+	AlertMetricExceptionRate = "exception_rate"
+)
+
+//PerformanceWindow is a carrier's performance over a time window, for one lane or, when the states are empty, across all lanes
+type PerformanceWindow struct {
+	Carrier            string
+	OriginState        string
+	DestinationState   string
+	ShipmentCount      int //shipments with tracking activity in the window
+	DeliveredCount     int //shipments delivered in the window
+	MedianTransitHours float64
+	ExceptionRate      float64
+}
+
+//Alert records a carrier performance anomaly
+type Alert struct {
+	AlertID          string     `json:"alert_id"`
+	TenantID         string     `json:"tenant_id"`
+	Carrier          string     `json:"carrier"`
+	OriginState      string     `json:"origin_state,omitempty"`
+	DestinationState string     `json:"destination_state,omitempty"`
+	Metric           string     `json:"metric"`
+	BaselineValue    float64    `json:"baseline_value"`
+	RecentValue      float64    `json:"recent_value"`
+	ThresholdValue   float64    `json:"threshold_value"`
+	BaselineSamples  int        `json:"baseline_samples"`
+	RecentSamples    int        `json:"recent_samples"`
+	CreatedAt        time.Time  `json:"created_at"`
+	DeliveredAt      *time.Time `json:"delivered_at,omitempty"`
+	DeliveryError    *string    `json:"delivery_error,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+//CarrierStats summarizes a carrier and service level's performance on a lane
+type CarrierStats struct {
+	Carrier            string  `json:"carrier"`
+	ServiceLevelToken  string  `json:"service_level,omitempty"`
+	SpeedClass         string  `json:"speed_class,omitempty"`
+	SampleCount        int     `json:"sample_count"`    //shipments on the lane
+	DeliveredCount     int     `json:"delivered_count"` //shipments with a time in transit
+	MedianTransitHours float64 `json:"median_transit_hours"`
+	P90TransitHours    float64 `json:"p90_transit_hours"`
+	OnTimeRate         float64 `json:"on_time_rate"`   //share of delivered shipments that arrived by their ETA or service level promise
+	ExceptionRate      float64 `json:"exception_rate"` //share of shipments with a failure, return or action required event
+}
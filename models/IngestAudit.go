@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+//IngestAuditEntry records one ingest attempt
+type IngestAuditEntry struct {
+	AuditID        string    `json:"audit_id"`
+	TenantID       string    `json:"-"`
+	ShipmentID     *string   `json:"shipment_id,omitempty"`
+	ClientID       string    `json:"client_id"`
+	KeyID          *string   `json:"key_id,omitempty"`
+	RequestID      *string   `json:"request_id,omitempty"`
+	Carrier        string    `json:"carrier"`
+	TrackingCode   string    `json:"tracking_code"`
+	Outcome        string    `json:"outcome"`
+	UpstreamStatus *int      `json:"upstream_status,omitempty"`
+	NewEvents      int       `json:"new_events"`
+	ChangedFields  []string  `json:"changed_fields"`
+	Error          *string   `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+//ShipmentSnapshot holds the stored shipment fields compared on re-ingest to find what changed
+type ShipmentSnapshot struct {
+	ShipmentID        string
+	ServiceLevelToken string
+	SpeedClass        string
+	ETA               *time.Time
+	OriginalETA       *time.Time
+	Test              bool
+}
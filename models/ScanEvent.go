@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+//ScanEvent is a tracking event reduced to where and when a shipment was scanned
+type ScanEvent struct {
+	ShipmentID string
+	Carrier    string
+	StatusDate time.Time
+	City       string //normalized
+	State      string //normalized
+	Status     string
+}
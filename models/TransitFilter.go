@@ -0,0 +1,13 @@
+package models
+
+//TransitFilter narrows analytics queries over delivered shipments, empty fields match everything
+type TransitFilter struct {
+	Carrier           string
+	ServiceLevelToken string
+	SpeedClass        string
+	OriginZip3        string
+	DestinationZip3   string
+	OriginState       string
+	DestinationState  string
+	Zone              int
+}
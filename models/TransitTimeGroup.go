@@ -0,0 +1,8 @@
+package models
+
+//TransitTimeGroup is the average time in transit of the shipments sharing a grouping value, such as a zone or distance band
+type TransitTimeGroup struct {
+	Group                string `json:"group"`
+	AverageTimeInTransit int    `json:"average_time_in_transit"` //milliseconds
+	Count                int    `json:"count"`
+}
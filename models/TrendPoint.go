@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+//TrendPoint aggregates the transit times of one carrier's shipments delivered within a time bucket.
+//Averages are nil for buckets without deliveries.
+type TrendPoint struct {
+	Bucket              time.Time `json:"bucket"`
+	Carrier             string    `json:"-"`
+	Count               int       `json:"count"`
+	AverageTransitHours *float64  `json:"average_transit_hours"`
+	MedianTransitHours  *float64  `json:"median_transit_hours"`
+	MovingAverageHours  *float64  `json:"moving_average_hours,omitempty"`
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	dataAccess "github.com/elorusso/wonderment-tech-eval/data-access"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+)
+
+//normalize-addresses recomputes the normalized address columns of a tenant's saved shipments and tracking events, and the shipments' lanes,
+//from the addresses as sent upstream. Normalization and lanes live in Go, so rows saved before the columns existed, or under older rules,
+//are backfilled here rather than by a migration.
+func main() {
+	tenantID := flag.String("tenant", "", "tenant whose rows are normalized (required)")
+	batchSize := flag.Int("batch", 500, "rows read per query")
+	flag.Parse()
+
+	if err := run(*tenantID, *batchSize); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func run(tenantID string, batchSize int) error {
+	if len(tenantID) == 0 {
+		return errors.New("-tenant is required")
+	}
+	if batchSize < 1 {
+		return errors.New("-batch must be at least 1")
+	}
+
+	databaseConn, err := dataAccess.NewSQLConnection()
+	if err != nil {
+		return err
+	}
+	defer databaseConn.Destroy()
+
+	log := logging.Default().With(logging.KeyTenantID, tenantID)
+	ctx := logging.WithLogger(context.Background(), log)
+	conn := databaseConn.WithLogger(log)
+
+	//shipments, paged by ID so updated rows are not read again
+	shipmentManager := conn.ShipmentManager(tenantID)
+	shipmentCount := 0
+	for after := ""; ; {
+		shipments, err := shipmentManager.ListShipmentAddresses(ctx, after, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(shipments) == 0 {
+			break
+		}
+
+		for _, shipment := range shipments {
+			if err := shipmentManager.UpdateNormalizedAddresses(ctx, shipment); err != nil {
+				return err
+			}
+		}
+		shipmentCount += len(shipments)
+		after = shipments[len(shipments)-1].ShipmentID
+		fmt.Printf("Normalized %d shipments\n", shipmentCount)
+	}
+
+	//tracking event locations
+	eventManager := conn.TrackingEventManager(tenantID)
+	eventCount := 0
+	for after := ""; ; {
+		events, err := eventManager.ListEventLocations(ctx, after, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		for _, event := range events {
+			if err := eventManager.UpdateNormalizedLocation(ctx, event); err != nil {
+				return err
+			}
+		}
+		eventCount += len(events)
+		after = events[len(events)-1].EventID
+		fmt.Printf("Normalized %d tracking events\n", eventCount)
+	}
+
+	fmt.Printf("Done: %d shipments and %d tracking events normalized\n", shipmentCount, eventCount)
+	return nil
+}
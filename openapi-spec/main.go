@@ -0,0 +1,10 @@
+package main
+
+import (
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/elorusso/wonderment-tech-eval/handlers"
+)
+
+func main() {
+	lambda.Start(handlers.Public(handlers.OpenAPISpec))
+}
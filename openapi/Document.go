@@ -0,0 +1,139 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+)
+
+//Version is the OpenAPI version documents are written in
+const Version = "3.0.3"
+
+//Document is the subset of an OpenAPI 3 document the API needs to describe itself
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+//PathItem holds the operations available on a path
+type PathItem struct {
+	Get  *Operation `json:"get,omitempty"`
+	Post *Operation `json:"post,omitempty"`
+}
+
+type Operation struct {
+	OperationID   string                `json:"operationId"`
+	Summary       string                `json:"summary"`
+	Description   string                `json:"description,omitempty"`
+	Tags          []string              `json:"tags,omitempty"`
+	Parameters    []*Parameter          `json:"parameters,omitempty"`
+	RequestBody   *RequestBody          `json:"requestBody,omitempty"`
+	Responses     map[string]*Response  `json:"responses"`
+	Security      []map[string][]string `json:"security,omitempty"`
+	RequiredScope string                `json:"x-required-scope,omitempty"` //API key scope the operation needs
+}
+
+//Parameter is a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Name        string `json:"name,omitempty"`
+	In          string `json:"in,omitempty"`
+	Scheme      string `json:"scheme,omitempty"`
+}
+
+//NewDocument creates an empty document
+func NewDocument(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{},
+		},
+	}
+}
+
+//AddOperation documents the operation under the path and method
+func (doc *Document) AddOperation(path string, method string, op *Operation) {
+	item, ok := doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		doc.Paths[path] = item
+	}
+
+	switch method {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPost:
+		item.Post = op
+	}
+}
+
+//Operation returns the operation documented for the method, or nil
+func (item *PathItem) Operation(method string) *Operation {
+	switch method {
+	case http.MethodGet:
+		return item.Get
+	case http.MethodPost:
+		return item.Post
+	}
+	return nil
+}
+
+//Lookup finds the operation for a request. API Gateway may prefix paths with a stage, so a documented path matching the end of the request path is accepted.
+func (doc *Document) Lookup(method string, path string) *Operation {
+	if item, ok := doc.Paths[path]; ok {
+		return item.Operation(method)
+	}
+	for documented, item := range doc.Paths {
+		if strings.HasSuffix(path, documented) {
+			return item.Operation(method)
+		}
+	}
+	return nil
+}
+
+//Resolve follows a component reference, returning other schemas unchanged
+func (doc *Document) Resolve(schema *Schema) *Schema {
+	for schema != nil && len(schema.Ref) > 0 {
+		schema = doc.Components.Schemas[strings.TrimPrefix(schema.Ref, componentPrefix)]
+	}
+	return schema
+}
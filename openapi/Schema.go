@@ -0,0 +1,162 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const componentPrefix = "#/components/schemas/"
+
+//Schema is the subset of an OpenAPI schema object used to describe parameters and bodies
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+//String describes a string, limited to the values when any are given
+func String(values ...string) *Schema {
+	return &Schema{Type: "string", Enum: values}
+}
+
+//Integer describes an integer of at least min
+func Integer(min int) *Schema {
+	minimum := float64(min)
+	return &Schema{Type: "integer", Minimum: &minimum}
+}
+
+//IntegerBetween describes an integer from min to max inclusive
+func IntegerBetween(min int, max int) *Schema {
+	minimum, maximum := float64(min), float64(max)
+	return &Schema{Type: "integer", Minimum: &minimum, Maximum: &maximum}
+}
+
+//Number describes a number strictly between min and max
+func Number(min float64, max float64) *Schema {
+	return &Schema{Type: "number", Minimum: &min, Maximum: &max, ExclusiveMinimum: true, ExclusiveMaximum: true}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+//SchemaFor describes the JSON encoding of the value's type. Named structs are added to the document's components and referenced,
+//so types shared by several responses, such as models.Alert, are described once.
+func (doc *Document) SchemaFor(value interface{}) *Schema {
+	return doc.schemaForType(reflect.TypeOf(value))
+}
+
+func (doc *Document) schemaForType(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		schema := doc.schemaForType(t.Elem())
+		if len(schema.Ref) > 0 {
+			//nullable cannot sit next to a reference, the referenced object describes the value
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct:
+		return doc.structSchema(t)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: doc.schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: doc.schemaForType(t.Elem())}
+	}
+	return &Schema{}
+}
+
+//structSchema describes a struct by its exported fields, named as encoding/json names them
+func (doc *Document) structSchema(t reflect.Type) *Schema {
+	name := componentName(t)
+	if len(name) > 0 {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			//reserve the name first so self referencing types terminate
+			doc.Components.Schemas[name] = &Schema{}
+			*doc.Components.Schemas[name] = *doc.objectSchema(t)
+		}
+		return &Schema{Ref: componentPrefix + name}
+	}
+	return doc.objectSchema(t)
+}
+
+func (doc *Document) objectSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if len(field.PkgPath) > 0 && !field.Anonymous {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+
+		//embedded structs are flattened, as encoding/json does
+		if field.Anonymous && len(tag[0]) == 0 && field.Type.Kind() == reflect.Struct {
+			embedded := doc.objectSchema(field.Type)
+			for name, property := range embedded.Properties {
+				schema.Properties[name] = property
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		name := tag[0]
+		if len(name) == 0 {
+			name = field.Name
+		}
+		schema.Properties[name] = doc.schemaForType(field.Type)
+
+		omitEmpty := false
+		for _, option := range tag[1:] {
+			omitEmpty = omitEmpty || option == "omitempty"
+		}
+		if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+//componentName is the type's name with its first letter capitalized, or empty for anonymous types
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	if len(name) == 0 {
+		return ""
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,65 @@
+package openapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elorusso/wonderment-tech-eval/openapi"
+)
+
+type address struct {
+	City *string
+}
+
+type shipment struct {
+	TrackingNumber string         `json:"tracking_number"`
+	Carrier        string         //encoding/json uses the field name
+	ETA            time.Time      `json:"eta,omitempty"`
+	From           *address       `json:"address_from"`
+	History        []*address     `json:"history"`
+	Counts         map[string]int `json:"counts"`
+	Secret         string         `json:"-"`
+	internal       string
+}
+
+func TestSchemaFor(t *testing.T) {
+	doc := openapi.NewDocument(openapi.Info{Title: "test", Version: "1"})
+
+	ref := doc.SchemaFor(shipment{})
+	if ref.Ref != "#/components/schemas/Shipment" {
+		t.Fatalf("named struct not referenced: %+v", ref)
+	}
+
+	schema := doc.Resolve(ref)
+	expected := map[string]string{"tracking_number": "string", "Carrier": "string", "eta": "string", "history": "array", "counts": "object"}
+	for name, schemaType := range expected {
+		if property, ok := schema.Properties[name]; !ok || property.Type != schemaType {
+			t.Errorf("expected %s to be a %s, got %+v", name, schemaType, property)
+		}
+	}
+	if len(schema.Properties) != 6 {
+		t.Errorf("unexpected properties %v", schema.Properties)
+	}
+	if schema.Properties["eta"].Format != "date-time" {
+		t.Error("times should be date-time strings")
+	}
+	if schema.Properties["counts"].AdditionalProperties.Type != "integer" {
+		t.Error("map values not described")
+	}
+
+	//omitempty fields are optional
+	for _, name := range schema.Required {
+		if name == "eta" {
+			t.Error("omitempty field marked required")
+		}
+	}
+
+	//shared types are described once
+	address := doc.Resolve(schema.Properties["address_from"])
+	if address == nil || !address.Properties["City"].Nullable {
+		t.Errorf("pointer fields should be nullable: %+v", address)
+	}
+	if schema.Properties["history"].Items.Ref != schema.Properties["address_from"].Ref {
+		t.Error("shared type not referenced")
+	}
+}
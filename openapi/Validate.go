@@ -0,0 +1,272 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+const (
+	MediaTypeJSON = "application/json"
+	MediaTypeForm = "application/x-www-form-urlencoded"
+)
+
+//Validate checks the request's parameters and body against the operation. Every offending field is reported in a single
+//validation_failed *models.APIError, an undocumented body media type is rejected with a 415, and nil is returned for valid requests.
+//Bodies that are not valid JSON are left for the handler to reject.
+func (doc *Document) Validate(op *Operation, payload *models.APIGatewayPayload) error {
+	var problems []models.FieldError
+
+	for _, param := range op.Parameters {
+		var value string
+		var present bool
+		switch param.In {
+		case "path":
+			value, present = payload.PathParameters[param.Name]
+		case "query":
+			value = payload.QueryParam(param.Name)
+			present = len(value) > 0
+		default:
+			continue
+		}
+
+		if !present {
+			if param.Required {
+				problems = append(problems, models.FieldError{Field: param.Name, Code: models.FieldCodeRequired, Message: fmt.Sprintf("%s is required", param.Name)})
+			}
+			continue
+		}
+		problems = append(problems, doc.validateString(param.Name, value, doc.Resolve(param.Schema))...)
+	}
+
+	if op.RequestBody != nil {
+		bodyProblems, err := doc.validateBody(op.RequestBody, payload)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, bodyProblems...)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	apiErr := models.NewAPIError(http.StatusBadRequest, models.ErrorCodeValidationFailed, problems[0].Message)
+	if len(problems) > 1 {
+		apiErr.Message = fmt.Sprintf("%d request parameters are invalid", len(problems))
+	}
+	apiErr.Details = problems
+	return apiErr
+}
+
+func (doc *Document) validateBody(body *RequestBody, payload *models.APIGatewayPayload) ([]models.FieldError, error) {
+	data, err := payload.DecodedBody()
+	if err != nil || len(data) == 0 {
+		//undecodable bodies are the handler's to reject, missing ones only matter when required
+		if err == nil && body.Required {
+			return []models.FieldError{{Field: "body", Code: models.FieldCodeRequired, Message: "Request body is required"}}, nil
+		}
+		return nil, nil
+	}
+
+	//requests without a content type are read as JSON, media types are case-insensitive
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(payload.Header("content-type"), ";")[0]))
+	if len(mediaType) == 0 {
+		mediaType = MediaTypeJSON
+	}
+	content, ok := body.Content[mediaType]
+	if !ok {
+		var supported []string
+		for documented := range body.Content {
+			supported = append(supported, documented)
+		}
+		sort.Strings(supported)
+		return nil, models.NewAPIError(http.StatusUnsupportedMediaType, models.ErrorCodeUnsupportedMediaType, fmt.Sprintf("Content type %q is not supported, expected %s", mediaType, strings.Join(supported, " or ")))
+	}
+	schema := doc.Resolve(content.Schema)
+
+	if mediaType == MediaTypeForm {
+		form, err := url.ParseQuery(string(data))
+		if err != nil {
+			return nil, nil
+		}
+		var problems []models.FieldError
+		for _, name := range sortedProperties(schema) {
+			if value := form.Get(name); len(value) > 0 {
+				problems = append(problems, doc.validateString(name, value, doc.Resolve(schema.Properties[name]))...)
+			} else if contains(schema.Required, name) {
+				problems = append(problems, models.FieldError{Field: name, Code: models.FieldCodeRequired, Message: fmt.Sprintf("%s is required", name)})
+			}
+		}
+		return problems, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, nil
+	}
+	return doc.validateValue("", value, schema), nil
+}
+
+//validateString checks a parameter, which arrives as a string whatever its schema's type
+func (doc *Document) validateString(name string, value string, schema *Schema) []models.FieldError {
+	if schema == nil {
+		return nil
+	}
+
+	switch schema.Type {
+	case "integer":
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return []models.FieldError{{Field: name, Code: models.FieldCodeInvalidType, Message: fmt.Sprintf("%s must be an integer", name)}}
+		}
+		return checkRange(name, float64(parsed), schema)
+	case "number":
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+			return []models.FieldError{{Field: name, Code: models.FieldCodeInvalidType, Message: fmt.Sprintf("%s must be a number", name)}}
+		}
+		return checkRange(name, parsed, schema)
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return []models.FieldError{{Field: name, Code: models.FieldCodeInvalidType, Message: fmt.Sprintf("%s must be true or false", name)}}
+		}
+		return nil
+	}
+	return checkEnum(name, value, schema)
+}
+
+//validateValue checks a decoded JSON value, naming nested fields with dots
+func (doc *Document) validateValue(name string, value interface{}, schema *Schema) []models.FieldError {
+	schema = doc.Resolve(schema)
+	if schema == nil || len(schema.Type) == 0 {
+		return nil
+	}
+
+	field := name
+	if len(field) == 0 {
+		field = "body"
+	}
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return []models.FieldError{{Field: field, Code: models.FieldCodeInvalidType, Message: fmt.Sprintf("%s must not be null", field)}}
+	}
+
+	mismatch := []models.FieldError{{Field: field, Code: models.FieldCodeInvalidType, Message: fmt.Sprintf("%s must be %s", field, article(schema.Type))}}
+	switch schema.Type {
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return mismatch
+		}
+		return checkEnum(field, text, schema)
+	case "integer", "number":
+		number, ok := value.(float64)
+		if !ok || (schema.Type == "integer" && number != math.Trunc(number)) {
+			return mismatch
+		}
+		return checkRange(field, number, schema)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		var problems []models.FieldError
+		for i, item := range items {
+			problems = append(problems, doc.validateValue(fmt.Sprintf("%s[%d]", field, i), item, schema.Items)...)
+		}
+		return problems
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+		var problems []models.FieldError
+		for _, property := range sortedProperties(schema) {
+			propertyName := property
+			if len(name) > 0 {
+				propertyName = name + "." + property
+			}
+			if propertyValue, ok := object[property]; ok {
+				problems = append(problems, doc.validateValue(propertyName, propertyValue, schema.Properties[property])...)
+			} else if contains(schema.Required, property) {
+				problems = append(problems, models.FieldError{Field: propertyName, Code: models.FieldCodeRequired, Message: fmt.Sprintf("%s is required", propertyName)})
+			}
+		}
+		return problems
+	}
+	return nil
+}
+
+func checkRange(name string, value float64, schema *Schema) []models.FieldError {
+	if schema.Minimum != nil && (value < *schema.Minimum || (schema.ExclusiveMinimum && value == *schema.Minimum)) ||
+		schema.Maximum != nil && (value > *schema.Maximum || (schema.ExclusiveMaximum && value == *schema.Maximum)) {
+		return []models.FieldError{{Field: name, Code: models.FieldCodeOutOfRange, Message: fmt.Sprintf("%s must be %s", name, describeRange(schema))}}
+	}
+	return nil
+}
+
+func describeRange(schema *Schema) string {
+	format := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	switch {
+	case schema.Minimum != nil && schema.Maximum != nil:
+		return fmt.Sprintf("between %s and %s", format(*schema.Minimum), format(*schema.Maximum))
+	case schema.Minimum != nil && schema.ExclusiveMinimum:
+		return "greater than " + format(*schema.Minimum)
+	case schema.Minimum != nil:
+		return "at least " + format(*schema.Minimum)
+	case schema.ExclusiveMaximum:
+		return "less than " + format(*schema.Maximum)
+	default:
+		return "at most " + format(*schema.Maximum)
+	}
+}
+
+func checkEnum(name string, value string, schema *Schema) []models.FieldError {
+	if len(schema.Enum) == 0 || contains(schema.Enum, value) {
+		return nil
+	}
+	return []models.FieldError{{Field: name, Code: models.FieldCodeUnknownValue, Message: fmt.Sprintf("%s must be one of %s", name, strings.Join(schema.Enum, ", "))}}
+}
+
+func article(schemaType string) string {
+	switch schemaType {
+	case "integer", "array", "object":
+		return "an " + schemaType
+	case "boolean":
+		return "true or false"
+	}
+	return "a " + schemaType
+}
+
+func sortedProperties(schema *Schema) []string {
+	var names []string
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
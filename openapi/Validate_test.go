@@ -0,0 +1,128 @@
+package openapi_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/models"
+	"github.com/elorusso/wonderment-tech-eval/openapi"
+)
+
+type ingestBody struct {
+	TrackingCode string   `json:"tracking_code"`
+	Count        int      `json:"count,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+}
+
+func testDocument() (*openapi.Document, *openapi.Operation) {
+	doc := openapi.NewDocument(openapi.Info{Title: "test", Version: "1"})
+	op := &openapi.Operation{
+		Parameters: []*openapi.Parameter{
+			{Name: "carrier", In: "query", Required: true, Schema: openapi.String()},
+			{Name: "limit", In: "query", Schema: openapi.Integer(1)},
+			{Name: "confidence", In: "query", Schema: openapi.Number(0, 1)},
+			{Name: "group_by", In: "query", Schema: openapi.String("zone", "distance_band")},
+		},
+		RequestBody: &openapi.RequestBody{
+			Content: map[string]*openapi.MediaType{
+				openapi.MediaTypeJSON: {Schema: doc.SchemaFor(ingestBody{})},
+				openapi.MediaTypeForm: {Schema: doc.SchemaFor(ingestBody{})},
+			},
+		},
+	}
+	doc.AddOperation("/ingest", http.MethodPost, op)
+	return doc, op
+}
+
+func validationDetails(t *testing.T, err error) map[string]string {
+	var apiErr *models.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an API error, got %v", err)
+	}
+	details := map[string]string{}
+	for _, detail := range apiErr.Details {
+		details[detail.Field] = detail.Code
+	}
+	return details
+}
+
+func TestValidate(t *testing.T) {
+	doc, op := testDocument()
+
+	valid := &models.APIGatewayPayload{
+		QueryStringParameters: map[string]string{"carrier": "ups", "limit": "10", "confidence": "0.9", "group_by": "zone"},
+		Body:                  `{"tracking_code":"1Z","tags":["a"]}`,
+	}
+	if err := doc.Validate(op, valid); err != nil {
+		t.Errorf("valid request rejected: %v", err)
+	}
+
+	invalid := &models.APIGatewayPayload{
+		QueryStringParameters: map[string]string{"limit": "0", "confidence": "high", "group_by": "state"},
+		Body:                  `{"count":1.5,"tags":[1]}`,
+	}
+	expected := map[string]string{
+		"carrier":       models.FieldCodeRequired,
+		"limit":         models.FieldCodeOutOfRange,
+		"confidence":    models.FieldCodeInvalidType,
+		"group_by":      models.FieldCodeUnknownValue,
+		"tracking_code": models.FieldCodeRequired,
+		"count":         models.FieldCodeInvalidType,
+		"tags[0]":       models.FieldCodeInvalidType,
+	}
+	details := validationDetails(t, doc.Validate(op, invalid))
+	for field, code := range expected {
+		if details[field] != code {
+			t.Errorf("expected %s to fail with %s, got %q", field, code, details[field])
+		}
+	}
+	if len(details) != len(expected) {
+		t.Errorf("unexpected details %v", details)
+	}
+}
+
+func TestValidateForm(t *testing.T) {
+	doc, op := testDocument()
+
+	//media types are case-insensitive, so each is read as a form
+	for _, contentType := range []string{"application/x-www-form-urlencoded; charset=utf-8", "Application/X-WWW-Form-Urlencoded"} {
+		payload := &models.APIGatewayPayload{
+			Headers:               map[string]string{"content-type": contentType},
+			QueryStringParameters: map[string]string{"carrier": "ups"},
+			Body:                  "count=many",
+		}
+		details := validationDetails(t, doc.Validate(op, payload))
+		if details["count"] != models.FieldCodeInvalidType || details["tracking_code"] != models.FieldCodeRequired {
+			t.Errorf("unexpected details for %q: %v", contentType, details)
+		}
+	}
+}
+
+func TestValidateMediaType(t *testing.T) {
+	doc, op := testDocument()
+
+	payload := &models.APIGatewayPayload{
+		Headers:               map[string]string{"content-type": "text/plain"},
+		QueryStringParameters: map[string]string{"carrier": "ups"},
+		Body:                  "1Z",
+	}
+	var apiErr *models.APIError
+	if err := doc.Validate(op, payload); !errors.As(err, &apiErr) || apiErr.Status != http.StatusUnsupportedMediaType {
+		t.Errorf("expected a 415, got %v", err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	doc, op := testDocument()
+
+	if doc.Lookup(http.MethodPost, "/ingest") != op {
+		t.Error("exact path not found")
+	}
+	if doc.Lookup(http.MethodPost, "/prod/ingest") != op {
+		t.Error("stage prefixed path not found")
+	}
+	if doc.Lookup(http.MethodGet, "/ingest") != nil || doc.Lookup(http.MethodPost, "/other") != nil {
+		t.Error("undocumented operation found")
+	}
+}
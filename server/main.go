@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/elorusso/wonderment-tech-eval/auth"
+	"github.com/elorusso/wonderment-tech-eval/handlers"
+	"github.com/elorusso/wonderment-tech-eval/logging"
+	"github.com/elorusso/wonderment-tech-eval/metrics"
+	"github.com/elorusso/wonderment-tech-eval/models"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	flag.Parse()
+
+	log := logging.Default()
+	log.Info("listening", "addr", *addr)
+
+	err := http.ListenAndServe(*addr, NewRouter())
+	if err != nil {
+		log.Error("server stopped", logging.KeyError, err)
+		os.Exit(1)
+	}
+}
+
+//NewRouter mounts every Lambda handler at the path its API Gateway route uses
+func NewRouter() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("/ingest-shipment", allowMethods(handlers.HTTPHandler(handlers.API(auth.ScopeIngest, handlers.IngestShipment)), http.MethodGet, http.MethodPost, http.MethodOptions))
+	mux.Handle("/average-time-in-transit", allowMethods(handlers.HTTPHandler(handlers.API(auth.ScopeAnalytics, handlers.AverageTimeInTransit)), http.MethodGet, http.MethodOptions))
+	mux.Handle("/facility-dwell-times", allowMethods(handlers.HTTPHandler(handlers.API(auth.ScopeAnalytics, handlers.FacilityDwellTimes)), http.MethodGet, http.MethodOptions))
+	mux.Handle("/predict-delivery", allowMethods(handlers.HTTPHandler(handlers.API(auth.ScopeAnalytics, handlers.PredictDelivery)), http.MethodGet, http.MethodOptions))
+	mux.Handle("/compare-carriers", allowMethods(handlers.HTTPHandler(handlers.API(auth.ScopeAnalytics, handlers.CompareCarriers)), http.MethodGet, http.MethodOptions))
+	mux.Handle("/transit-trend", allowMethods(handlers.HTTPHandler(handlers.API(auth.ScopeAnalytics, handlers.TransitTrend)), http.MethodGet, http.MethodOptions))
+	mux.Handle("/alerts", allowMethods(handlers.HTTPHandler(handlers.API(auth.ScopeAnalytics, handlers.ListAlerts)), http.MethodGet, http.MethodOptions))
+	mux.Handle("/shipment-audit-trail", allowMethods(handlers.HTTPHandler(handlers.API(auth.ScopeAudit, handlers.ShipmentAuditTrail)), http.MethodGet, http.MethodOptions))
+	mux.Handle("/openapi.json", allowMethods(handlers.HTTPHandler(handlers.Public(handlers.OpenAPISpec)), http.MethodGet, http.MethodOptions))
+
+	//Prometheus scrape endpoint, left unauthenticated for the scraper
+	mux.Handle("/metrics", allowMethods(metrics.DefaultRegistry.Handler(), http.MethodGet))
+
+	return mux
+}
+
+func allowMethods(next http.Handler, methods ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, method := range methods {
+			if r.Method == method {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		handlers.WriteError(r.Context(), w, models.NewAPIError(http.StatusMethodNotAllowed, models.ErrorCodeMethodNotAllowed, "Method Not Allowed"))
+	})
+}
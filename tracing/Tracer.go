@@ -0,0 +1,239 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+//TraceparentHeader is the W3C Trace Context header carrying the trace and parent span IDs between services
+const TraceparentHeader = "traceparent"
+
+//SpanKind follows the OpenTelemetry span kinds
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+//Span times one operation within a trace
+type Span struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Name         string
+	Kind         SpanKind
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]interface{}
+	Err          error
+
+	tracer    *Tracer
+	localRoot bool //the first span of the trace in this process, its end flushes the trace
+	mu        sync.Mutex
+	ended     bool
+}
+
+//SetAttribute records a key value pair on the span
+func (span *Span) SetAttribute(key string, value interface{}) {
+	if span == nil {
+		return
+	}
+	span.mu.Lock()
+	defer span.mu.Unlock()
+	span.Attributes[key] = value
+}
+
+//RecordError marks the span as failed
+func (span *Span) RecordError(err error) {
+	if span == nil || err == nil {
+		return
+	}
+	span.mu.Lock()
+	defer span.mu.Unlock()
+	span.Err = err
+}
+
+//Finish ends the span, further calls do nothing. Ending a local root span exports the spans buffered for its trace.
+func (span *Span) Finish() {
+	if span == nil {
+		return
+	}
+	span.mu.Lock()
+	if span.ended {
+		span.mu.Unlock()
+		return
+	}
+	span.ended = true
+	span.End = time.Now()
+	span.mu.Unlock()
+
+	span.tracer.finished(span)
+}
+
+//Traceparent formats the span as a W3C traceparent header value, for the services it calls
+func (span *Span) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-01", span.TraceID, span.SpanID)
+}
+
+//Exporter sends finished spans to a backend
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+//Tracer creates spans and exports each trace once its local root span finishes
+type Tracer struct {
+	exporter Exporter
+	mu       sync.Mutex
+	pending  map[string][]*Span //finished spans by trace ID
+	onError  func(error)
+}
+
+//NewTracer returns a tracer exporting to the exporter, or only propagating trace IDs when it is nil
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{
+		exporter: exporter,
+		pending:  map[string][]*Span{},
+		onError:  func(err error) { fmt.Fprintln(os.Stderr, "trace export failed:", err) },
+	}
+}
+
+type contextKey struct{}
+
+type remoteParent struct {
+	traceID string
+	spanID  string
+}
+
+type remoteContextKey struct{}
+
+//Start begins a span that is a child of the context's span, or of a remote parent added with WithRemoteParent, or starts a new trace
+func (tracer *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	span := &Span{
+		SpanID:     newID(8),
+		Name:       name,
+		Kind:       kind,
+		Start:      time.Now(),
+		Attributes: map[string]interface{}{},
+		tracer:     tracer,
+	}
+
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentSpanID = parent.SpanID
+	} else if remote, ok := ctx.Value(remoteContextKey{}).(remoteParent); ok {
+		span.TraceID = remote.traceID
+		span.ParentSpanID = remote.spanID
+		span.localRoot = true
+	} else {
+		span.TraceID = newID(16)
+		span.localRoot = true
+	}
+
+	return context.WithValue(ctx, contextKey{}, span), span
+}
+
+func (tracer *Tracer) finished(span *Span) {
+	if tracer.exporter == nil {
+		return
+	}
+
+	tracer.mu.Lock()
+	tracer.pending[span.TraceID] = append(tracer.pending[span.TraceID], span)
+	if !span.localRoot {
+		tracer.mu.Unlock()
+		return
+	}
+	spans := tracer.pending[span.TraceID]
+	delete(tracer.pending, span.TraceID)
+	tracer.mu.Unlock()
+
+	if err := tracer.exporter.Export(spans); err != nil {
+		tracer.onError(err)
+	}
+}
+
+//SpanFromContext returns the context's current span, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(contextKey{}).(*Span)
+	return span
+}
+
+//WithRemoteParent continues the trace in a W3C traceparent header value, invalid values are ignored
+func WithRemoteParent(ctx context.Context, traceparent string) context.Context {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 || !isHex(parts[1]) || !isHex(parts[2]) {
+		return ctx
+	}
+	if parts[1] == strings.Repeat("0", 32) || parts[2] == strings.Repeat("0", 16) {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteContextKey{}, remoteParent{traceID: strings.ToLower(parts[1]), spanID: strings.ToLower(parts[2])})
+}
+
+//Inject adds the traceparent header for the context's span to an outgoing request
+func Inject(ctx context.Context, header http.Header) {
+	if span := SpanFromContext(ctx); span != nil {
+		header.Set(TraceparentHeader, span.Traceparent())
+	}
+}
+
+func isHex(value string) bool {
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
+
+func newID(size int) string {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		//fall back to the clock, IDs only need to be unique within a trace backend
+		return fmt.Sprintf("%0*x", size*2, time.Now().UnixNano())[:size*2]
+	}
+	return hex.EncodeToString(data)
+}
+
+var (
+	defaultTracer *Tracer
+	defaultOnce   sync.Once
+)
+
+//Default returns the process tracer, configured by TRACING_EXPORTER: stdout, otlp (sent to OTEL_EXPORTER_OTLP_ENDPOINT,
+//default http://localhost:4318) or empty to only propagate trace IDs. OTEL_SERVICE_NAME names the service.
+func Default() *Tracer {
+	defaultOnce.Do(func() {
+		serviceName := os.Getenv("OTEL_SERVICE_NAME")
+		if len(serviceName) == 0 {
+			serviceName = "wonderment-tech-eval"
+		}
+
+		var exporter Exporter
+		switch strings.ToLower(os.Getenv("TRACING_EXPORTER")) {
+		case "stdout":
+			exporter = NewStdoutExporter(os.Stdout)
+		case "otlp":
+			endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+			if len(endpoint) == 0 {
+				endpoint = "http://localhost:4318"
+			}
+			exporter = NewOTLPExporter(endpoint, serviceName)
+		}
+		defaultTracer = NewTracer(exporter)
+	})
+	return defaultTracer
+}
+
+//Start begins a span on the default tracer
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	return Default().Start(ctx, name, kind)
+}
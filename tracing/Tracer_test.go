@@ -0,0 +1,121 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/elorusso/wonderment-tech-eval/tracing"
+)
+
+type recordingExporter struct {
+	mu      sync.Mutex
+	exports [][]*tracing.Span
+}
+
+func (exporter *recordingExporter) Export(spans []*tracing.Span) error {
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	exporter.exports = append(exporter.exports, spans)
+	return nil
+}
+
+func TestTracerExportsTraceWhenRootFinishes(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := tracing.NewTracer(exporter)
+
+	ctx, root := tracer.Start(context.Background(), "HandleRequest", tracing.KindServer)
+	childCtx, child := tracer.Start(ctx, "LimitedTrackingSerice", tracing.KindClient)
+	child.RecordError(errors.New("timeout"))
+	child.Finish()
+
+	if len(exporter.exports) != 0 {
+		t.Fatalf("expected nothing exported before the root finishes")
+	}
+
+	header := http.Header{}
+	tracing.Inject(childCtx, header)
+	expected := "00-" + root.TraceID + "-" + child.SpanID + "-01"
+	if header.Get(tracing.TraceparentHeader) != expected {
+		t.Errorf("expected traceparent %s, got %s", expected, header.Get(tracing.TraceparentHeader))
+	}
+
+	root.Finish()
+	root.Finish()
+
+	if len(exporter.exports) != 1 || len(exporter.exports[0]) != 2 {
+		t.Fatalf("expected one export of two spans, got %+v", exporter.exports)
+	}
+	exported := exporter.exports[0][0]
+	if exported.Name != "LimitedTrackingSerice" || exported.TraceID != root.TraceID || exported.ParentSpanID != root.SpanID || exported.Err == nil {
+		t.Errorf("unexpected child span %+v", exported)
+	}
+	if len(root.TraceID) != 32 || len(root.SpanID) != 16 || len(root.ParentSpanID) != 0 {
+		t.Errorf("unexpected root IDs %+v", root)
+	}
+}
+
+func TestWithRemoteParent(t *testing.T) {
+	tracer := tracing.NewTracer(nil)
+
+	ctx := tracing.WithRemoteParent(context.Background(), "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01")
+	_, span := tracer.Start(ctx, "HandleRequest", tracing.KindServer)
+	if span.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || span.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("expected the remote trace to continue, got %+v", span)
+	}
+
+	for _, invalid := range []string{"", "00-xyz-00f067aa0ba902b7-01", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"} {
+		_, span := tracer.Start(tracing.WithRemoteParent(context.Background(), invalid), "HandleRequest", tracing.KindServer)
+		if len(span.ParentSpanID) != 0 {
+			t.Errorf("expected a new trace for %q, got parent %s", invalid, span.ParentSpanID)
+		}
+	}
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var out bytes.Buffer
+	tracer := tracing.NewTracer(tracing.NewStdoutExporter(&out))
+
+	_, span := tracer.Start(context.Background(), "InsertShipment", tracing.KindInternal)
+	span.SetAttribute("carrier", "ups")
+	span.Finish()
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("expected a JSON line, got %q", out.String())
+	}
+	if line["name"] != "InsertShipment" || line["trace_id"] != span.TraceID || line["attributes"].(map[string]interface{})["carrier"] != "ups" {
+		t.Errorf("unexpected line %v", line)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		path, body = r.URL.Path, string(data)
+	}))
+	defer server.Close()
+
+	tracer := tracing.NewTracer(tracing.NewOTLPExporter(server.URL+"/", "test-service"))
+	_, span := tracer.Start(context.Background(), "InsertTrackingEvent", tracing.KindInternal)
+	span.SetAttribute("events", 3)
+	span.RecordError(errors.New("duplicate"))
+	span.Finish()
+
+	if path != "/v1/traces" {
+		t.Errorf("unexpected path %s", path)
+	}
+	for _, expected := range []string{`"stringValue":"test-service"`, `"traceId":"` + span.TraceID + `"`, `"name":"InsertTrackingEvent"`, `"intValue":"3"`, `"code":2`} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("missing %s in %s", expected, body)
+		}
+	}
+}